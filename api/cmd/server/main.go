@@ -1,27 +1,51 @@
 package main
 
 import (
+	"api/internal/blob"
 	"api/internal/config"
 	"api/internal/controller"
+	"api/internal/jobs"
+	"api/internal/logging"
+	"api/internal/metrics"
+	"api/internal/ratelimit"
 	"api/internal/router"
+	"api/internal/server"
 	"api/internal/storage"
+	"api/internal/tracing"
+	"context"
 	"database/sql"
 	"fmt"
-	"log"
-	"net/http"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 
 	_ "github.com/lib/pq"
+	"golang.org/x/crypto/acme/autocert"
 )
 
 func failIf(err error, msg string) {
 	if err != nil {
-		log.Fatalf("error: %s: %v", msg, err)
+		slog.Error(msg, "error", err)
+		os.Exit(1)
 	}
 }
 
 func main() {
 	config, err := config.New()
 	failIf(err, "parse configuration")
+	logger, err := logging.New(os.Stdout, config)
+	failIf(err, "set up logging")
+	slog.SetDefault(logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := tracing.Setup(ctx, config)
+	failIf(err, "set up tracing")
+	defer shutdownTracing(context.Background())
+
 	dsn := fmt.Sprintf(
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
 		config.GetString("postgres.host"),
@@ -32,10 +56,46 @@ func main() {
 	)
 	db, err := sql.Open("postgres", dsn)
 	failIf(err, "open database connection")
+	defer db.Close()
+	err = server.WaitFor(ctx, db, config.GetInt("postgres.connect_attempts"), config.GetDuration("postgres.connect_delay"))
+	failIf(err, "connect to database")
+
+	metrics := metrics.New()
+	metrics.RegisterDB(db, config.GetString("postgres.dbname"))
 	storage := storage.New(db)
-	controller := controller.New(storage, config)
-	router := router.New(controller, config)
-	addr := fmt.Sprintf("%s:%s", config.GetString("server.host"), config.GetString("server.port"))
-	fmt.Printf("listening on %s\n", addr)
-	http.ListenAndServe(addr, router)
+	storage.Event = tracing.EventAccess(metrics.EventAccess(storage.Event))
+	blobs, err := blob.NewStore(config)
+	failIf(err, "open attachment store")
+	controller, err := controller.New(storage, blobs, config)
+	failIf(err, "create controller")
+
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	purger, err := jobs.NewPurger(storage.Event, storage.Attachment, blobs, config)
+	failIf(err, "create purger")
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		purger.Run(workersCtx)
+	}()
+
+	router, err := router.New(controller, config, metrics, ratelimit.NewMemoryStore(),
+		router.Check{Name: "database", Check: db.PingContext},
+		router.Check{Name: "schema", Check: storage.CheckSchema},
+		router.Check{Name: "purger", Check: purger.Check},
+	)
+	failIf(err, "create router")
+
+	srv, err := server.New(router, config, autocert.DirCache(config.GetString("server.tls.acme.cache_dir")))
+	failIf(err, "create server")
+	slog.Info("listening", "addr", srv.Addr())
+	err = srv.Run(ctx)
+	stopWorkers()
+	workers.Wait()
+	if err != nil {
+		slog.Error("serve", "error", err)
+		shutdownTracing(context.Background())
+		db.Close()
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,55 @@
+// Package blob stores the contents of files, like event attachments, apart
+// from their metadata in the database.
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/viper"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+// Store keeps blobs under keys chosen by the caller. Keys consist of letters,
+// digits, dashes and dots. Put takes a size of -1 if it is not known.
+type Store interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// NewStore creates the store selected by attachments.store in config, either
+// "fs" or "s3".
+func NewStore(config *viper.Viper) (Store, error) {
+	switch kind := config.GetString("attachments.store"); kind {
+	case "fs", "":
+		return NewFS(config.GetString("attachments.fs.path"))
+	case "s3":
+		return NewS3(S3Config{
+			Endpoint:  config.GetString("attachments.s3.endpoint"),
+			Region:    config.GetString("attachments.s3.region"),
+			Bucket:    config.GetString("attachments.s3.bucket"),
+			AccessKey: config.GetString("attachments.s3.access_key"),
+			SecretKey: config.GetString("attachments.s3.secret_key"),
+		})
+	default:
+		return nil, fmt.Errorf("attachments.store %q not supported", kind)
+	}
+}
+
+func validKey(key string) bool {
+	if key == "" || key[0] == '.' {
+		return false
+	}
+	for _, r := range key {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9', r == '-', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,44 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFS(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFS(filepath.Join(dir, "blobs"))
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	err = store.Put(ctx, "slides", strings.NewReader("hello"), 5, "text/plain")
+	assert.NoError(t, err)
+
+	rc, err := store.Get(ctx, "slides")
+	if assert.NoError(t, err) {
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		assert.Equal(t, "hello", string(b))
+	}
+
+	err = store.Put(ctx, "short", strings.NewReader("hi"), 5, "text/plain")
+	assert.Error(t, err)
+	_, err = store.Get(ctx, "short")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	entries, err := os.ReadDir(filepath.Join(dir, "blobs"))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	assert.NoError(t, store.Delete(ctx, "slides"))
+	assert.ErrorIs(t, store.Delete(ctx, "slides"), ErrNotFound)
+
+	_, err = store.Get(ctx, "../slides")
+	assert.Error(t, err)
+}
@@ -0,0 +1,81 @@
+// Package calendar computes the boundaries of local days, weeks and months.
+// Boundaries are midnights in the given location, so a window spanning a DST
+// transition is an hour shorter or longer than its nominal length.
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Calendar numbers weeks starting on WeekStart. Week 1 of a year is the first
+// week with at least four days in that year, which for Monday is the ISO 8601
+// week date.
+type Calendar struct {
+	WeekStart time.Weekday
+}
+
+var ISO = Calendar{WeekStart: time.Monday}
+
+func ParseWeekday(s string) (time.Weekday, error) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(s, d.String()) {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown weekday %q", s)
+}
+
+// Day returns the window from midnight of the given day in loc to the next
+// midnight.
+func Day(year int, month time.Month, day int, loc *time.Location) (start, end time.Time) {
+	return time.Date(year, month, day, 0, 0, 0, 0, loc), time.Date(year, month, day+1, 0, 0, 0, 0, loc)
+}
+
+// Month returns the window from midnight of the first day of the month in
+// loc to midnight of the first day of the next month.
+func Month(year int, month time.Month, loc *time.Location) (start, end time.Time) {
+	return time.Date(year, month, 1, 0, 0, 0, 0, loc), time.Date(year, month+1, 1, 0, 0, 0, 0, loc)
+}
+
+// Week returns the window of the given week of year in loc.
+func (c Calendar) Week(year, week int, loc *time.Location) (start, end time.Time, err error) {
+	if n := c.WeeksInYear(year); week < 1 || week > n {
+		return time.Time{}, time.Time{}, fmt.Errorf("week %d out of range, %d has %d weeks", week, year, n)
+	}
+	day := c.firstDay(year) + 7*(week-1)
+	return time.Date(year, time.January, day, 0, 0, 0, 0, loc), time.Date(year, time.January, day+7, 0, 0, 0, 0, loc), nil
+}
+
+// WeekOf returns the year and week in which t falls in the location of t.
+func (c Calendar) WeekOf(t time.Time) (year, week int) {
+	year = t.Year()
+	yday := t.YearDay()
+	if first := c.firstDay(year + 1); yday >= first+daysIn(year) {
+		return year + 1, 1
+	}
+	first := c.firstDay(year)
+	if yday < first {
+		year--
+		first = c.firstDay(year) - daysIn(year)
+	}
+	return year, (yday-first)/7 + 1
+}
+
+// WeeksInYear returns 52 or 53.
+func (c Calendar) WeeksInYear(year int) int {
+	return (c.firstDay(year+1) + daysIn(year) - c.firstDay(year)) / 7
+}
+
+// firstDay returns the day of January on which week 1 of year starts. It is
+// the start of the week containing January 4th and may be zero or negative,
+// i.e. in December of the previous year.
+func (c Calendar) firstDay(year int) int {
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	return 4 - (int(jan4.Weekday())-int(c.WeekStart)+7)%7
+}
+
+func daysIn(year int) int {
+	return time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+}
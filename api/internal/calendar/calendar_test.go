@@ -0,0 +1,145 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func load(t *testing.T, name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func TestParseWeekday(t *testing.T) {
+	d, err := ParseWeekday("sunday")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Sunday, d)
+	d, err = ParseWeekday("Monday")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Monday, d)
+	_, err = ParseWeekday("caturday")
+	assert.Error(t, err)
+}
+
+func TestDay(t *testing.T) {
+	tests := []struct {
+		name  string
+		loc   string
+		date  time.Time
+		hours float64
+	}{
+		{"Regular", "Europe/Berlin", time.Date(2023, time.March, 25, 0, 0, 0, 0, time.UTC), 24},
+		{"Spring Forward", "Europe/Berlin", time.Date(2023, time.March, 26, 0, 0, 0, 0, time.UTC), 23},
+		{"Fall Back", "Europe/Berlin", time.Date(2023, time.October, 29, 0, 0, 0, 0, time.UTC), 25},
+		{"Spring Forward New York", "America/New_York", time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC), 23},
+		{"Half Hour Shift", "Australia/Lord_Howe", time.Date(2023, time.October, 1, 0, 0, 0, 0, time.UTC), 23.5},
+		{"UTC", "UTC", time.Date(2023, time.October, 29, 0, 0, 0, 0, time.UTC), 24},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := load(t, tt.loc)
+			start, end := Day(tt.date.Year(), tt.date.Month(), tt.date.Day(), loc)
+			assert.Equal(t, tt.hours, end.Sub(start).Hours())
+			assert.Equal(t, 0, start.Hour())
+			assert.Equal(t, 0, end.Hour())
+			assert.Equal(t, tt.date.Day(), start.Day())
+		})
+	}
+}
+
+func TestMonth(t *testing.T) {
+	tests := []struct {
+		name  string
+		loc   string
+		year  int
+		month time.Month
+		hours float64
+	}{
+		{"March With Spring Forward", "Europe/Berlin", 2023, time.March, 31*24 - 1},
+		{"October With Fall Back", "Europe/Berlin", 2023, time.October, 31*24 + 1},
+		{"Leap February", "UTC", 2024, time.February, 29 * 24},
+		{"December", "America/New_York", 2023, time.December, 31 * 24},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := Month(tt.year, tt.month, load(t, tt.loc))
+			assert.Equal(t, tt.hours, end.Sub(start).Hours())
+			assert.Equal(t, 1, start.Day())
+			assert.Equal(t, 1, end.Day())
+		})
+	}
+}
+
+func TestWeek(t *testing.T) {
+	berlin := load(t, "Europe/Berlin")
+	tests := []struct {
+		name      string
+		cal       Calendar
+		year      int
+		week      int
+		wantStart time.Time
+		hours     float64
+	}{
+		{"ISO Week 1 In Previous Year", ISO, 2020, 1, time.Date(2019, time.December, 30, 0, 0, 0, 0, berlin), 168},
+		{"ISO Week 53", ISO, 2020, 53, time.Date(2020, time.December, 28, 0, 0, 0, 0, berlin), 168},
+		{"ISO Week 53 Of 2026", ISO, 2026, 53, time.Date(2026, time.December, 28, 0, 0, 0, 0, berlin), 168},
+		{"ISO Week 1 In Same Year", ISO, 2024, 1, time.Date(2024, time.January, 1, 0, 0, 0, 0, berlin), 168},
+		{"ISO Week With Spring Forward", ISO, 2023, 12, time.Date(2023, time.March, 20, 0, 0, 0, 0, berlin), 167},
+		{"ISO Week With Fall Back", ISO, 2023, 43, time.Date(2023, time.October, 23, 0, 0, 0, 0, berlin), 169},
+		{"Sunday Start", Calendar{time.Sunday}, 2023, 1, time.Date(2023, time.January, 1, 0, 0, 0, 0, berlin), 168},
+		{"Sunday Start With Fall Back", Calendar{time.Sunday}, 2023, 44, time.Date(2023, time.October, 29, 0, 0, 0, 0, berlin), 169},
+		{"Saturday Start", Calendar{time.Saturday}, 2022, 1, time.Date(2022, time.January, 1, 0, 0, 0, 0, berlin), 168},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := tt.cal.Week(tt.year, tt.week, berlin)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStart, start)
+			assert.Equal(t, tt.hours, end.Sub(start).Hours())
+			assert.Equal(t, tt.cal.WeekStart, start.Weekday())
+		})
+	}
+
+	t.Run("Out Of Range", func(t *testing.T) {
+		_, _, err := ISO.Week(2021, 53, berlin)
+		assert.Error(t, err)
+		_, _, err = ISO.Week(2021, 0, berlin)
+		assert.Error(t, err)
+	})
+}
+
+func TestWeeksInYear(t *testing.T) {
+	for year, want := range map[int]int{2015: 53, 2019: 52, 2020: 53, 2021: 52, 2026: 53, 2032: 53, 2100: 52} {
+		assert.Equal(t, want, ISO.WeeksInYear(year), "%d", year)
+	}
+}
+
+func TestWeekOf(t *testing.T) {
+	t.Run("Matches ISOWeek", func(t *testing.T) {
+		for d := time.Date(2014, time.December, 1, 12, 0, 0, 0, time.UTC); d.Year() < 2033; d = d.AddDate(0, 0, 1) {
+			wantYear, wantWeek := d.ISOWeek()
+			year, week := ISO.WeekOf(d)
+			if !assert.Equal(t, [2]int{wantYear, wantWeek}, [2]int{year, week}, "%s", d.Format(time.DateOnly)) {
+				return
+			}
+		}
+	})
+
+	t.Run("Round Trips", func(t *testing.T) {
+		for _, start := range []time.Weekday{time.Sunday, time.Monday, time.Saturday} {
+			cal := Calendar{start}
+			for d := time.Date(2019, time.December, 1, 12, 0, 0, 0, time.UTC); d.Year() < 2028; d = d.AddDate(0, 0, 1) {
+				year, week := cal.WeekOf(d)
+				from, to, err := cal.Week(year, week, time.UTC)
+				if !assert.NoError(t, err) || !assert.True(t, !d.Before(from) && d.Before(to), "%s %s", start, d.Format(time.DateOnly)) {
+					return
+				}
+			}
+		}
+	})
+}
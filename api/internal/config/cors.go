@@ -0,0 +1,39 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/spf13/viper"
+)
+
+var defaultCORSMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
+
+type CORS struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowCredentials bool
+}
+
+// CORSFrom reads the cors section of cfg. Without allowed_origins any origin
+// is allowed, which cannot be combined with credentials.
+func CORSFrom(cfg *viper.Viper) (CORS, error) {
+	cors := CORS{
+		AllowedOrigins:   cfg.GetStringSlice("cors.allowed_origins"),
+		AllowedMethods:   cfg.GetStringSlice("cors.allowed_methods"),
+		AllowCredentials: cfg.GetBool("cors.allow_credentials"),
+	}
+	if len(cors.AllowedOrigins) == 0 {
+		cors.AllowedOrigins = []string{"*"}
+	}
+	if len(cors.AllowedMethods) == 0 {
+		cors.AllowedMethods = defaultCORSMethods
+	}
+	if cors.AllowCredentials {
+		for _, origin := range cors.AllowedOrigins {
+			if origin == "*" {
+				return CORS{}, errors.New("cors.allow_credentials requires explicit cors.allowed_origins")
+			}
+		}
+	}
+	return cors, nil
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORSFrom(t *testing.T) {
+	cfg := viper.New()
+	cors, err := CORSFrom(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"*"}, cors.AllowedOrigins)
+	assert.Equal(t, defaultCORSMethods, cors.AllowedMethods)
+
+	cfg.Set("cors.allow_credentials", true)
+	_, err = CORSFrom(cfg)
+	assert.Error(t, err)
+
+	cfg.Set("cors.allowed_origins", []string{"https://calendar.example.com"})
+	cfg.Set("cors.allowed_methods", []string{"GET"})
+	cors, err = CORSFrom(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, CORS{AllowedOrigins: []string{"https://calendar.example.com"}, AllowedMethods: []string{"GET"}, AllowCredentials: true}, cors)
+}
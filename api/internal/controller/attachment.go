@@ -0,0 +1,210 @@
+package controller
+
+import (
+	"api/internal/blob"
+	"api/internal/logging"
+	"api/internal/models"
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultAttachmentMaxSize = 10 << 20
+	// sniffLen is the number of bytes http.DetectContentType considers.
+	sniffLen = 512
+)
+
+func (c *Controller) attachmentMaxSize() int64 {
+	if c.config.IsSet("attachments.max_size") {
+		return c.config.GetInt64("attachments.max_size")
+	}
+	return defaultAttachmentMaxSize
+}
+
+func (c *Controller) GetAttachments(w http.ResponseWriter, r *http.Request) {
+	atts, err := c.storage.Attachment.GetByEvent(mux.Vars(r)["uuid"])
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, atts)
+}
+
+// CreateAttachment stores the "file" part of a multipart/form-data body. The
+// content type is sniffed from the contents rather than taken from the client.
+// The event is looked up first, so uploads to missing or trashed events are
+// refused before their contents are read. The contents are streamed to the
+// blob store before the attachment is recorded, so a listed attachment always
+// has them.
+func (c *Controller) CreateAttachment(w http.ResponseWriter, r *http.Request) {
+	eventUUID := mux.Vars(r)["uuid"]
+	if _, err := c.events(r).GetByUUID(eventUUID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	maxSize := c.attachmentMaxSize()
+	// Leave room for the multipart framing around the file.
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+1<<20)
+	mr, err := r.MultipartReader()
+	if err != nil {
+		WriteProblem(w, r, http.StatusUnsupportedMediaType, codeUnsupportedMedia, err.Error())
+		return
+	}
+	var part *multipart.Part
+	for {
+		part, err = mr.NextPart()
+		if err == io.EOF {
+			writeBadRequest(w, r, errors.New(`multipart form has no "file" part`))
+			return
+		}
+		if err != nil {
+			writeUploadError(w, r, err, maxSize)
+			return
+		}
+		if part.FormName() == "file" {
+			break
+		}
+	}
+	name := filepath.Base(part.FileName())
+	if name == "" || name == "." || name == string(filepath.Separator) {
+		writeBadRequest(w, r, errors.New("file name is required"))
+		return
+	}
+
+	br := bufio.NewReaderSize(http.MaxBytesReader(w, part, maxSize), sniffLen)
+	head, err := br.Peek(sniffLen)
+	if err != nil && err != io.EOF {
+		writeUploadError(w, r, err, maxSize)
+		return
+	}
+	body := &countingReader{r: br}
+	att := models.Attachment{
+		UUID:        uuid.New().String(),
+		EventUUID:   eventUUID,
+		Name:        name,
+		ContentType: sniffContentType(name, head),
+	}
+	err = c.blobs.Put(r.Context(), att.UUID, body, -1, att.ContentType)
+	if body.err != nil {
+		writeUploadError(w, r, body.err, maxSize)
+		return
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	att.Size = body.n
+	err = c.storage.Attachment.Create(&att)
+	if err != nil {
+		if derr := c.blobs.Delete(r.Context(), att.UUID); derr != nil {
+			logError(r, "remove blob of failed upload", derr)
+		}
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, att)
+}
+
+func (c *Controller) GetAttachment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	att, err := c.storage.Attachment.Get(vars["uuid"], vars["id"])
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	rc, err := c.blobs.Get(r.Context(), att.UUID)
+	if errors.Is(err, blob.ErrNotFound) {
+		logError(r, "attachment without contents", err)
+		WriteProblem(w, r, http.StatusNotFound, codeNotFound, "attachment contents do not exist")
+		return
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	defer rc.Close()
+	w.Header().Set("Content-Type", att.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(att.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": att.Name}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, rc); err != nil {
+		logging.FromContext(r.Context()).Warn("attachment download interrupted", "error", err, "path", r.URL.Path)
+	}
+}
+
+func (c *Controller) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	err := c.storage.Attachment.Delete(vars["uuid"], vars["id"])
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	// The metadata is gone, so a blob left behind is only wasted space.
+	err = c.blobs.Delete(r.Context(), vars["id"])
+	if err != nil && !errors.Is(err, blob.ErrNotFound) {
+		logError(r, "delete attachment blob", err)
+	}
+	writeKV(w, http.StatusOK, "message", "success")
+}
+
+// sniffContentType detects the type from the first bytes of data. Formats that
+// sniff as a generic container, like office documents in zip, fall back to the
+// type registered for the file extension.
+func sniffContentType(name string, data []byte) string {
+	ct := http.DetectContentType(data)
+	switch ct {
+	case "application/octet-stream", "application/zip", "text/plain; charset=utf-8":
+		if ext := mime.TypeByExtension(filepath.Ext(name)); ext != "" && !isActiveContent(ext) {
+			return ext
+		}
+	}
+	return ct
+}
+
+// isActiveContent reports whether a browser would render or run the type.
+// Those are only served when the contents sniff as such.
+func isActiveContent(ct string) bool {
+	mt, _, _ := mime.ParseMediaType(ct)
+	switch mt {
+	case "text/html", "image/svg+xml", "application/javascript", "text/javascript", "application/xhtml+xml":
+		return true
+	}
+	return false
+}
+
+func writeUploadError(w http.ResponseWriter, r *http.Request, err error, maxSize int64) {
+	var merr *http.MaxBytesError
+	if errors.As(err, &merr) {
+		WriteProblem(w, r, http.StatusRequestEntityTooLarge, codeTooLarge, fmt.Sprintf("attachment exceeds %d bytes", maxSize))
+		return
+	}
+	writeMalformedBody(w, r, err)
+}
+
+// countingReader counts the bytes read from r and keeps the first error other
+// than io.EOF, to tell a failed upload from a failed store.
+type countingReader struct {
+	r   io.Reader
+	n   int64
+	err error
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	if err != nil && err != io.EOF && cr.err == nil {
+		cr.err = err
+	}
+	return n, err
+}
@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"api/internal/models"
+	"api/internal/validation"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const (
+	// batchAtomic applies all operations or none of them.
+	batchAtomic = "atomic"
+	// batchPerItem commits the operations that succeed and reports the
+	// ones that fail.
+	batchPerItem = "per_item"
+)
+
+type batchOperation struct {
+	Op      string        `json:"op"`
+	UUID    string        `json:"uuid,omitempty"`
+	Version int           `json:"version,omitempty"`
+	Event   *models.Event `json:"event,omitempty"`
+}
+
+type batchRequest struct {
+	Mode       string           `json:"mode"`
+	Operations []batchOperation `json:"operations"`
+}
+
+type batchResult struct {
+	Status  int            `json:"status"`
+	UUID    string         `json:"uuid,omitempty"`
+	Version int            `json:"version,omitempty"`
+	Error   map[string]any `json:"error,omitempty"`
+}
+
+var errBatchAborted = errors.New("batch aborted")
+
+func (c *Controller) BatchEvents(w http.ResponseWriter, r *http.Request) {
+	var req batchRequest
+	err := decodeJSON(r, &req)
+	if err != nil {
+		writeMalformedBody(w, r, err)
+		return
+	}
+	switch req.Mode {
+	case "":
+		req.Mode = batchAtomic
+	case batchAtomic, batchPerItem:
+	default:
+		WriteProblem(w, r, http.StatusBadRequest, codeBadRequest, fmt.Sprintf("batch mode %q not supported", req.Mode))
+		return
+	}
+	if maxOps := c.config.GetInt("events.batch.max_operations"); maxOps > 0 && len(req.Operations) > maxOps {
+		WriteProblem(w, r, http.StatusBadRequest, codeBadRequest, fmt.Sprintf("batch must not contain more than %d operations", maxOps))
+		return
+	}
+
+	results := make([]batchResult, len(req.Operations))
+	err = c.events(r).InTx(func(tx models.EventAccess) error {
+		for i, op := range req.Operations {
+			run := func(ea models.EventAccess) error {
+				return c.runBatchOperation(ea, op, &results[i])
+			}
+			var err error
+			if req.Mode == batchAtomic {
+				err = run(tx)
+			} else {
+				err = tx.InTx(run)
+			}
+			if err == nil {
+				continue
+			}
+
+			statusCode, code, detail, kvPairs := classify(err)
+			if statusCode == http.StatusInternalServerError {
+				logError(r, "batch operation failed", err)
+			}
+			results[i] = batchResult{Status: statusCode, UUID: op.UUID, Error: problemMembers(code, detail, kvPairs)}
+			if req.Mode == batchAtomic {
+				return errBatchAborted
+			}
+		}
+		return nil
+	})
+	committed := err == nil
+	if errors.Is(err, errBatchAborted) {
+		for i := range results {
+			if results[i].Error == nil {
+				results[i] = batchResult{
+					Status: http.StatusFailedDependency,
+					UUID:   req.Operations[i].UUID,
+					Error:  problemMembers(codeBatchAborted, "another operation of the batch failed", nil),
+				}
+			}
+		}
+	} else if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeKVs(w, http.StatusOK, "committed", committed, "results", results)
+}
+
+func (c *Controller) runBatchOperation(ea models.EventAccess, op batchOperation, res *batchResult) error {
+	// The version stands in for If-Match, so the same policy applies to it.
+	if (op.Op == "update" || op.Op == "delete") && op.Version <= 0 && c.config.GetBool("events.require_if_match") {
+		return errVersionRequired
+	}
+	switch op.Op {
+	case "create", "update":
+		if op.Event == nil {
+			return validation.Errors{{Field: "event", Code: "required", Message: "must be set for " + op.Op}}
+		}
+		evt := *op.Event
+		evt.Normalize()
+		if err := c.rules.Event(&evt); err != nil {
+			return err
+		}
+		if op.Op == "create" {
+			uuid, err := ea.Create(&evt)
+			if err != nil {
+				return err
+			}
+			*res = batchResult{Status: http.StatusOK, UUID: uuid, Version: evt.Version}
+			return nil
+		}
+		evt.UUID = op.UUID
+		evt.Version = op.Version
+		if err := ea.Update(&evt); err != nil {
+			return err
+		}
+		*res = batchResult{Status: http.StatusOK, UUID: evt.UUID, Version: evt.Version}
+		return nil
+	case "delete":
+		if err := ea.Delete(op.UUID, op.Version); err != nil {
+			return err
+		}
+		*res = batchResult{Status: http.StatusOK, UUID: op.UUID}
+		return nil
+	}
+	return validation.Errors{{Field: "op", Code: "unsupported", Message: fmt.Sprintf("%q is not one of create, update, delete", op.Op)}}
+}
+
+func problemMembers(code, detail string, kvPairs []any) map[string]any {
+	m := map[string]any{
+		"code":   code,
+		"detail": detail,
+	}
+	for i := range kvPairs {
+		if i%2 == 0 {
+			m[kvPairs[i].(string)] = kvPairs[i+1]
+		}
+	}
+	return m
+}
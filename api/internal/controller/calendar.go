@@ -0,0 +1,9 @@
+package controller
+
+import "net/http"
+
+// GetCalendar returns the settings the client needs to number weeks the way
+// GetEventsByWeek does. week_start counts from 0 for Sunday.
+func (c *Controller) GetCalendar(w http.ResponseWriter, r *http.Request) {
+	writeKV(w, http.StatusOK, "week_start", int(c.calendar.WeekStart))
+}
@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"api/internal/models"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultCommentLimit = 50
+	maxCommentLimit     = 200
+)
+
+// GetComments returns a page of the comments of an event, oldest first. The
+// page is selected by the limit and offset query parameters.
+func (c *Controller) GetComments(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit, offset := defaultCommentLimit, 0
+	if query.Has("limit") {
+		var err error
+		limit, err = strconv.Atoi(query.Get("limit"))
+		if err != nil {
+			writeBadRequest(w, r, err)
+			return
+		}
+		if limit < 1 || limit > maxCommentLimit {
+			writeBadRequest(w, r, fmt.Errorf("limit must be between 1 and %d", maxCommentLimit))
+			return
+		}
+	}
+	if query.Has("offset") {
+		var err error
+		offset, err = strconv.Atoi(query.Get("offset"))
+		if err != nil {
+			writeBadRequest(w, r, err)
+			return
+		}
+		if offset < 0 {
+			writeBadRequest(w, r, fmt.Errorf("offset must not be negative"))
+			return
+		}
+	}
+	comments, err := c.storage.Comment.GetByEvent(mux.Vars(r)["uuid"], limit, offset)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, comments)
+}
+
+func (c *Controller) CreateComment(w http.ResponseWriter, r *http.Request) {
+	var comment models.Comment
+	err := decodeJSON(r, &comment)
+	if err != nil {
+		writeMalformedBody(w, r, err)
+		return
+	}
+	err = c.rules.Comment(&comment)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	comment.EventUUID = mux.Vars(r)["uuid"]
+	err = c.storage.Comment.Create(&comment)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, comment)
+}
+
+// UpdateComment edits the body of a comment. The author is kept.
+func (c *Controller) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	var body models.Comment
+	err = decodeJSON(r, &body)
+	if err != nil {
+		writeMalformedBody(w, r, err)
+		return
+	}
+	comment, err := c.storage.Comment.Get(vars["uuid"], id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	comment.Body = body.Body
+	err = c.rules.Comment(comment)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	err = c.storage.Comment.Update(comment)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, comment)
+}
+
+func (c *Controller) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	err = c.storage.Comment.Delete(vars["uuid"], id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeKV(w, http.StatusOK, "message", "success")
+}
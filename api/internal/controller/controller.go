@@ -1,23 +1,50 @@
 package controller
 
 import (
+	"api/internal/blob"
+	"api/internal/calendar"
+	"api/internal/models"
 	"api/internal/storage"
+	"api/internal/validation"
 	"encoding/json"
+	"fmt"
 	"net/http"
 
 	"github.com/spf13/viper"
 )
 
 type Controller struct {
-	storage *storage.Storage
-	config  *viper.Viper
+	storage  *storage.Storage
+	blobs    blob.Store
+	config   *viper.Viper
+	rules    validation.Rules
+	calendar calendar.Calendar
 }
 
-func New(storage *storage.Storage, config *viper.Viper) *Controller {
-	return &Controller{
-		storage: storage,
-		config:  config,
+func New(storage *storage.Storage, blobs blob.Store, config *viper.Viper) (*Controller, error) {
+	rules, err := validation.NewRules(config)
+	if err != nil {
+		return nil, err
+	}
+	cal := calendar.ISO
+	if config.IsSet("calendar.week_start") {
+		cal.WeekStart, err = calendar.ParseWeekday(config.GetString("calendar.week_start"))
+		if err != nil {
+			return nil, fmt.Errorf("calendar.week_start: %w", err)
+		}
 	}
+	return &Controller{
+		storage:  storage,
+		blobs:    blobs,
+		config:   config,
+		rules:    rules,
+		calendar: cal,
+	}, nil
+}
+
+// events returns the event storage bound to the context of r.
+func (c *Controller) events(r *http.Request) models.EventAccess {
+	return c.storage.Event.WithContext(r.Context())
 }
 
 func writeJSON(w http.ResponseWriter, statusCode int, data any) error {
@@ -42,3 +69,9 @@ func writeKVs(w http.ResponseWriter, statusCode int, kvPairs ...any) error {
 
 	return writeJSON(w, statusCode, m)
 }
+
+func decodeJSON(r *http.Request, v any) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
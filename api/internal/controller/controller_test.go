@@ -0,0 +1,210 @@
+package controller
+
+import (
+	"api/internal/blob"
+	"api/internal/models"
+	"api/internal/storage"
+	"api/internal/validation"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEvents keeps events in memory. Methods the tests do not need panic via
+// the nil embedded interface.
+type fakeEvents struct {
+	models.EventAccess
+	events map[string]*models.Event
+}
+
+func newFakeEvents(evts ...models.Event) *fakeEvents {
+	f := &fakeEvents{events: map[string]*models.Event{}}
+	for i := range evts {
+		f.events[evts[i].UUID] = &evts[i]
+	}
+	return f
+}
+
+func (f *fakeEvents) GetByUUID(uuid string) (*models.Event, error) {
+	evt, ok := f.events[uuid]
+	if !ok {
+		return nil, &models.StorageError{Kind: models.ErrNotFound, Detail: "event does not exist"}
+	}
+	cp := *evt
+	return &cp, nil
+}
+
+func (f *fakeEvents) Update(evt *models.Event) error {
+	stored, err := f.GetByUUID(evt.UUID)
+	if err != nil {
+		return err
+	}
+	if evt.Version != 0 && evt.Version != stored.Version {
+		return &models.StorageError{Kind: models.ErrPrecondition, Detail: "event has changed"}
+	}
+	evt.Version = stored.Version + 1
+	cp := *evt
+	f.events[evt.UUID] = &cp
+	return nil
+}
+
+func (f *fakeEvents) Delete(uuid string, version int) error {
+	stored, err := f.GetByUUID(uuid)
+	if err != nil {
+		return err
+	}
+	if version != 0 && version != stored.Version {
+		return &models.StorageError{Kind: models.ErrPrecondition, Detail: "event has changed"}
+	}
+	delete(f.events, uuid)
+	return nil
+}
+
+func (f *fakeEvents) InTx(fn func(tx models.EventAccess) error) error {
+	return fn(f)
+}
+
+func (f *fakeEvents) WithContext(ctx context.Context) models.EventAccess {
+	return f
+}
+
+func newTestController(t *testing.T, config *viper.Viper, events models.EventAccess) *Controller {
+	c, err := New(&storage.Storage{Event: events}, nil, config)
+	require.NoError(t, err)
+	return c
+}
+
+func TestBatchEventsRequireVersion(t *testing.T) {
+	config := viper.New()
+	config.Set("events.require_if_match", true)
+	events := newFakeEvents(
+		models.Event{UUID: "a", Title: "A", Version: 1},
+		models.Event{UUID: "b", Title: "B", Version: 1},
+	)
+	c := newTestController(t, config, events)
+
+	body := `{"mode": "per_item", "operations": [
+		{"op": "delete", "uuid": "a"},
+		{"op": "update", "uuid": "b", "event": {"title": "B", "date_from": "2023-10-01T10:00:00Z", "date_to": "2023-10-01T11:00:00Z"}},
+		{"op": "delete", "uuid": "b", "version": 1}
+	]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/events/batch", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	c.BatchEvents(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var res struct {
+		Results []batchResult `json:"results"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+	var statuses []int
+	for _, r := range res.Results {
+		statuses = append(statuses, r.Status)
+	}
+	assert.Equal(t, []int{http.StatusPreconditionRequired, http.StatusPreconditionRequired, http.StatusOK}, statuses)
+	assert.Equal(t, codePreconditionReq, res.Results[0].Error["code"])
+	assert.Contains(t, events.events, "a")
+	assert.NotContains(t, events.events, "b")
+}
+
+func TestIfMatchVersion(t *testing.T) {
+	c := newTestController(t, viper.New(), newFakeEvents(models.Event{UUID: "a", Version: 3}))
+	tests := []struct {
+		ifMatch []string
+		want    int
+	}{
+		{nil, 0},
+		{[]string{"*"}, 0},
+		{[]string{`"3"`}, 3},
+		{[]string{`W/"3"`}, 3},
+		{[]string{`"x"`}, -1},
+		{[]string{`"2", W/"3"`}, 3},
+		{[]string{`"1"`, `"2"`}, -1},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPut, "/api/events/a", nil)
+		for _, v := range tt.ifMatch {
+			req.Header.Add("If-Match", v)
+		}
+		got, err := c.ifMatchVersion(req, "a")
+		assert.NoError(t, err, tt.ifMatch)
+		assert.Equal(t, tt.want, got, tt.ifMatch)
+	}
+}
+
+func TestNegativeLimit(t *testing.T) {
+	c := newTestController(t, viper.New(), newFakeEvents())
+	for path, handler := range map[string]http.HandlerFunc{
+		"/api/events?limit=-1":            c.GetEvents,
+		"/api/events/search?q=a&limit=-1": c.SearchEvents,
+	} {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, path)
+	}
+}
+
+// fakeBlobs counts the blobs stored. Methods the tests do not need panic via
+// the nil embedded interface.
+type fakeBlobs struct {
+	blob.Store
+	puts int
+}
+
+func (f *fakeBlobs) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	f.puts++
+	return nil
+}
+
+func TestCreateAttachmentMissingEvent(t *testing.T) {
+	blobs := &fakeBlobs{}
+	c, err := New(&storage.Storage{Event: newFakeEvents()}, blobs, viper.New())
+	require.NoError(t, err)
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("file", "agenda.txt")
+	require.NoError(t, err)
+	io.WriteString(fw, "1. Budget")
+	require.NoError(t, mw.Close())
+	req := httptest.NewRequest(http.MethodPost, "/api/events/a/attachments", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req = mux.SetURLVars(req, map[string]string{"uuid": "a"})
+	w := httptest.NewRecorder()
+	c.CreateAttachment(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Zero(t, blobs.puts)
+}
+
+func TestPatchedFieldsReadOnly(t *testing.T) {
+	current := &models.Event{UUID: "a", Title: "A", Version: 1}
+	deleted := time.Date(2023, time.October, 1, 0, 0, 0, 0, time.UTC)
+	patched := *current
+	patched.Title = "B"
+	patched.DeletedAt = &deleted
+
+	_, err := patchedFields(current, &patched)
+	var errs validation.Errors
+	if assert.ErrorAs(t, err, &errs) && assert.Len(t, errs, 1) {
+		assert.Equal(t, "deleted_at", errs[0].Field)
+		assert.Equal(t, "read_only", errs[0].Code)
+	}
+
+	patched.DeletedAt = nil
+	fields, err := patchedFields(current, &patched)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.EventField{models.Title}, fields)
+}
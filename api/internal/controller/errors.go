@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"api/internal/filter"
+	"api/internal/logging"
+	"api/internal/models"
+	"api/internal/validation"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const (
+	codeBadRequest       = "bad_request"
+	codeMalformedBody    = "malformed_body"
+	codeInvalidFilter    = "invalid_filter"
+	codeNotFound         = "not_found"
+	codeConflict         = "conflict"
+	codeValidation       = "validation_failed"
+	codeConstraint       = "constraint_violation"
+	codePrecondition     = "precondition_failed"
+	codePreconditionReq  = "precondition_required"
+	codePatchFailed      = "patch_failed"
+	codeUnsupportedMedia = "unsupported_media_type"
+	codeBatchAborted     = "batch_aborted"
+	codeTooLarge         = "too_large"
+	codeInternal         = "internal_error"
+)
+
+var (
+	errPreconditionRequired = errors.New("request must be conditional on the event version via If-Match")
+	errVersionRequired      = errors.New("operation must be conditional on the event version")
+)
+
+// WriteProblem writes an RFC 7807 problem details response. code is a stable
+// machine-readable identifier of the problem, kvPairs are added as extension
+// members. It is exported for the middleware in front of the controller, so
+// that all problems look the same.
+func WriteProblem(w http.ResponseWriter, r *http.Request, statusCode int, code, detail string, kvPairs ...any) error {
+	m := map[string]any{
+		"type":     "about:blank",
+		"title":    http.StatusText(statusCode),
+		"status":   statusCode,
+		"code":     code,
+		"detail":   detail,
+		"instance": r.URL.Path,
+	}
+	for i := range kvPairs {
+		if i%2 == 0 {
+			m[kvPairs[i].(string)] = kvPairs[i+1]
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(statusCode)
+	return json.NewEncoder(w).Encode(m)
+}
+
+func writeBadRequest(w http.ResponseWriter, r *http.Request, err error) error {
+	var ferr *filter.Error
+	if errors.As(err, &ferr) {
+		return WriteProblem(w, r, http.StatusBadRequest, codeInvalidFilter, ferr.Error(), "offset", ferr.Offset, "token", ferr.Token)
+	}
+	return WriteProblem(w, r, http.StatusBadRequest, codeBadRequest, err.Error())
+}
+
+func writeMalformedBody(w http.ResponseWriter, r *http.Request, err error) error {
+	var merr *http.MaxBytesError
+	if errors.As(err, &merr) {
+		return WriteProblem(w, r, http.StatusRequestEntityTooLarge, codeTooLarge, fmt.Sprintf("request body exceeds %d bytes", merr.Limit))
+	}
+	return WriteProblem(w, r, http.StatusBadRequest, codeMalformedBody, err.Error())
+}
+
+// logError logs err with the request it occurred in.
+func logError(r *http.Request, msg string, err error) {
+	logging.FromContext(r.Context()).Error(msg, "error", err, "method", r.Method, "path", r.URL.Path)
+}
+
+// writeError maps validation and storage errors to problem responses.
+// Unclassified errors are logged and answered with a generic 500.
+func writeError(w http.ResponseWriter, r *http.Request, err error) error {
+	statusCode, code, detail, kvPairs := classify(err)
+	if statusCode == http.StatusInternalServerError {
+		logError(r, "request failed", err)
+	}
+	return WriteProblem(w, r, statusCode, code, detail, kvPairs...)
+}
+
+// classify returns the status code, problem code, detail and extension
+// members describing err.
+func classify(err error) (int, string, string, []any) {
+	var verrs validation.Errors
+	if errors.As(err, &verrs) {
+		return http.StatusUnprocessableEntity, codeValidation, "event is invalid", []any{"errors", verrs}
+	}
+
+	if errors.Is(err, errPreconditionRequired) || errors.Is(err, errVersionRequired) {
+		return http.StatusPreconditionRequired, codePreconditionReq, err.Error(), nil
+	}
+
+	var (
+		statusCode int
+		code       string
+	)
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		statusCode, code = http.StatusNotFound, codeNotFound
+	case errors.Is(err, models.ErrConflict):
+		statusCode, code = http.StatusConflict, codeConflict
+	case errors.Is(err, models.ErrValidation):
+		statusCode, code = http.StatusUnprocessableEntity, codeValidation
+	case errors.Is(err, models.ErrConstraint):
+		statusCode, code = http.StatusUnprocessableEntity, codeConstraint
+	case errors.Is(err, models.ErrPrecondition):
+		statusCode, code = http.StatusPreconditionFailed, codePrecondition
+	default:
+		return http.StatusInternalServerError, codeInternal, "data access failure", nil
+	}
+
+	var serr *models.StorageError
+	if !errors.As(err, &serr) {
+		return statusCode, code, err.Error(), nil
+	}
+	if serr.Code != "" {
+		code = serr.Code
+	}
+	if len(serr.Conflicts) > 0 {
+		return statusCode, code, serr.Detail, []any{"conflicts", serr.Conflicts}
+	}
+	return statusCode, code, serr.Detail, nil
+}
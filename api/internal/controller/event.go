@@ -1,22 +1,32 @@
 package controller
 
 import (
+	"api/internal/calendar"
+	"api/internal/filter"
 	"api/internal/models"
-	"database/sql"
+	"api/internal/patch"
+	"api/internal/validation"
+	"bytes"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"mime"
 	"net/http"
-	"os"
+	"net/url"
+	"reflect"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
 )
 
+const acceptPatch = "application/merge-patch+json, application/json-patch+json"
+
 func (c *Controller) GetAllEvents(w http.ResponseWriter, r *http.Request) {
-	evts, err := c.storage.Event.GetAll()
+	evts, err := c.events(r).GetAll()
 	if err != nil {
-		writeKV(w, http.StatusInternalServerError, "message", "data access failure")
+		writeError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, evts)
@@ -28,30 +38,27 @@ func (c *Controller) GetEvents(w http.ResponseWriter, r *http.Request) {
 	var (
 		startDate time.Time
 		endDate   time.Time
+		where     filter.Expr
 		sortField models.EventField
 		sortOrder models.SortOrder
 		limit     int
 	)
 	{
-		if vars.Has("start") {
-			startVar := vars.Get("start")
-			start, err := time.Parse(time.RFC3339, startVar)
-			if err != nil {
-				writeKV(w, http.StatusBadRequest, "message", err.Error())
-				return
-			}
-			startDate = start.UTC()
+		var err error
+		startDate, endDate, err = parseWindow(vars)
+		if err != nil {
+			writeBadRequest(w, r, err)
+			return
 		}
 	}
 	{
-		if vars.Has("end") {
-			endVar := vars.Get("end")
-			end, err := time.Parse(time.RFC3339, endVar)
+		if vars.Has("filter") {
+			var err error
+			where, err = filter.Parse(vars.Get("filter"))
 			if err != nil {
-				writeKV(w, http.StatusBadRequest, "message", err.Error())
+				writeBadRequest(w, r, err)
 				return
 			}
-			endDate = end.UTC()
 		}
 	}
 	{
@@ -60,7 +67,11 @@ func (c *Controller) GetEvents(w http.ResponseWriter, r *http.Request) {
 			var err error
 			limit, err = strconv.Atoi(limitVar)
 			if err != nil {
-				writeKV(w, http.StatusBadRequest, "message", err.Error())
+				writeBadRequest(w, r, err)
+				return
+			}
+			if limit < 0 {
+				WriteProblem(w, r, http.StatusBadRequest, codeBadRequest, "query parameter limit must not be negative")
 				return
 			}
 		}
@@ -83,8 +94,10 @@ func (c *Controller) GetEvents(w http.ResponseWriter, r *http.Request) {
 				sortField = models.DateTo
 			case "created_at":
 				sortField = models.CreatedAt
+			case "updated_at":
+				sortField = models.UpdatedAt
 			default:
-				writeKV(w, http.StatusBadRequest, "message", fmt.Sprintf("query paramter sort=%s not supported", sortFieldVar))
+				WriteProblem(w, r, http.StatusBadRequest, codeBadRequest, fmt.Sprintf("query parameter sort=%s not supported", sortFieldVar))
 				return
 			}
 
@@ -95,38 +108,79 @@ func (c *Controller) GetEvents(w http.ResponseWriter, r *http.Request) {
 			case "desc":
 				sortOrder = models.Desc
 			default:
-				writeKV(w, http.StatusBadRequest, "message", fmt.Sprintf("query paramter order=%s not supported", sortOrderVar))
+				WriteProblem(w, r, http.StatusBadRequest, codeBadRequest, fmt.Sprintf("query parameter ord=%s not supported", sortOrderVar))
 				return
 			}
 		}
 	}
 
-	evts, err := c.storage.Event.GetByFilter(startDate, endDate, sortField, sortOrder, limit)
+	where = withTagParams(vars, where)
+
+	if c.notModified(w, r, startDate, endDate, where) {
+		return
+	}
+	evts, err := c.events(r).GetByFilter(startDate, endDate, where, sortField, sortOrder, limit)
 	if err != nil {
-		writeKV(w, http.StatusInternalServerError, "message", "data access failure")
-		fmt.Fprint(os.Stderr, err)
+		writeError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, evts)
 }
 
+func (c *Controller) SearchEvents(w http.ResponseWriter, r *http.Request) {
+	vars := r.URL.Query()
+
+	q := vars.Get("q")
+	if strings.TrimSpace(q) == "" {
+		WriteProblem(w, r, http.StatusBadRequest, codeBadRequest, "query parameter q is required")
+		return
+	}
+	startDate, endDate, err := parseWindow(vars)
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	var limit int
+	if vars.Has("limit") {
+		limit, err = strconv.Atoi(vars.Get("limit"))
+		if err != nil {
+			writeBadRequest(w, r, err)
+			return
+		}
+		if limit < 0 {
+			WriteProblem(w, r, http.StatusBadRequest, codeBadRequest, "query parameter limit must not be negative")
+			return
+		}
+	}
+
+	results, err := c.events(r).Search(q, startDate, endDate, limit)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
 func (c *Controller) GetEventsByDay(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	location, err := time.LoadLocation(vars["tz"])
+	location, err := models.LoadLocation(vars["tz"])
 	if err != nil {
-		writeKV(w, http.StatusBadRequest, "message", err.Error())
+		writeBadRequest(w, r, err)
 		return
 	}
-	startDate, err := time.ParseInLocation(time.DateOnly, vars["date"], location)
+	date, err := models.ParseDate(vars["date"])
 	if err != nil {
-		writeKV(w, http.StatusBadRequest, "message", err.Error())
+		writeBadRequest(w, r, err)
 		return
 	}
-	startDateUTC := startDate.UTC()
-	endDateUTC := startDateUTC.AddDate(0, 0, 1)
-	evts, err := c.storage.Event.GetByFilter(startDateUTC, endDateUTC, models.DateFrom, models.Asc, 0)
+	startDate, endDate := calendar.Day(date.Year, date.Month, date.Day, location)
+	where := withTagParams(r.URL.Query(), nil)
+	if c.notModified(w, r, startDate, endDate, where) {
+		return
+	}
+	evts, err := c.events(r).GetByFilter(startDate, endDate, where, models.DateFrom, models.Asc, 0)
 	if err != nil {
-		writeKV(w, http.StatusInternalServerError, "message", "data access failure")
+		writeError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, evts)
@@ -134,35 +188,33 @@ func (c *Controller) GetEventsByDay(w http.ResponseWriter, r *http.Request) {
 
 func (c *Controller) GetEventsByWeek(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	location, err := time.LoadLocation(vars["tz"])
+	location, err := models.LoadLocation(vars["tz"])
 	if err != nil {
-		writeKV(w, http.StatusBadRequest, "message", err.Error())
+		writeBadRequest(w, r, err)
 		return
 	}
 	year, err := strconv.Atoi(vars["year"])
 	if err != nil {
-		writeKV(w, http.StatusBadRequest, "message", err.Error())
+		writeBadRequest(w, r, err)
 		return
 	}
 	week, err := strconv.Atoi(vars["week"])
 	if err != nil {
-		writeKV(w, http.StatusBadRequest, "message", err.Error())
+		writeBadRequest(w, r, err)
 		return
 	}
-	t := time.Date(year, 1, 1, 0, 0, 0, 0, location)
-
-	for {
-		y, w := t.ISOWeek()
-		if y == year && w == 1 {
-			break
-		}
-		t = t.AddDate(0, 0, 1)
+	startDate, endDate, err := c.calendar.Week(year, week, location)
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	where := withTagParams(r.URL.Query(), nil)
+	if c.notModified(w, r, startDate, endDate, where) {
+		return
 	}
-	startDateUTC := t.Add(time.Duration(week-1) * 7 * 24 * time.Hour).UTC()
-	endDateUTC := startDateUTC.AddDate(0, 0, 7)
-	evts, err := c.storage.Event.GetByFilter(startDateUTC, endDateUTC, models.DateFrom, models.Asc, 0)
+	evts, err := c.events(r).GetByFilter(startDate, endDate, where, models.DateFrom, models.Asc, 0)
 	if err != nil {
-		writeKV(w, http.StatusInternalServerError, "message", "data access failure")
+		writeError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, evts)
@@ -170,26 +222,33 @@ func (c *Controller) GetEventsByWeek(w http.ResponseWriter, r *http.Request) {
 
 func (c *Controller) GetEventsByMonth(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	location, err := time.LoadLocation(vars["tz"])
+	location, err := models.LoadLocation(vars["tz"])
 	if err != nil {
-		writeKV(w, http.StatusBadRequest, "message", err.Error())
+		writeBadRequest(w, r, err)
 		return
 	}
 	year, err := strconv.Atoi(vars["year"])
 	if err != nil {
-		writeKV(w, http.StatusBadRequest, "message", err.Error())
+		writeBadRequest(w, r, err)
 		return
 	}
 	month, err := strconv.Atoi(vars["month"])
 	if err != nil {
-		writeKV(w, http.StatusBadRequest, "message", err.Error())
+		writeBadRequest(w, r, err)
 		return
 	}
-	startDateUTC := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, location).UTC()
-	endDateUTC := startDateUTC.AddDate(0, 1, 0)
-	evts, err := c.storage.Event.GetByFilter(startDateUTC, endDateUTC, models.DateFrom, models.Asc, 0)
+	if month < 1 || month > 12 {
+		WriteProblem(w, r, http.StatusBadRequest, codeBadRequest, fmt.Sprintf("month %d out of range", month))
+		return
+	}
+	startDate, endDate := calendar.Month(year, time.Month(month), location)
+	where := withTagParams(r.URL.Query(), nil)
+	if c.notModified(w, r, startDate, endDate, where) {
+		return
+	}
+	evts, err := c.events(r).GetByFilter(startDate, endDate, where, models.DateFrom, models.Asc, 0)
 	if err != nil {
-		writeKV(w, http.StatusInternalServerError, "message", "data access failure")
+		writeError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, evts)
@@ -197,66 +256,413 @@ func (c *Controller) GetEventsByMonth(w http.ResponseWriter, r *http.Request) {
 
 func (c *Controller) CreateEvent(w http.ResponseWriter, r *http.Request) {
 	var evt models.Event
-	err := json.NewDecoder(r.Body).Decode(&evt)
+	err := decodeJSON(r, &evt)
 	if err != nil {
-		writeKV(w, http.StatusBadRequest, "message", err.Error())
-		fmt.Fprint(os.Stderr, err)
+		writeMalformedBody(w, r, err)
 		return
 	}
-	evt.DateFrom = evt.DateFrom.UTC()
-	evt.DateTo = evt.DateTo.UTC()
-	uuid, err := c.storage.Event.Create(&evt)
+	evt.Normalize()
+	err = c.rules.Event(&evt)
 	if err != nil {
-		writeKV(w, http.StatusInternalServerError, "message", "data access failure")
-		fmt.Fprint(os.Stderr, err)
+		writeError(w, r, err)
 		return
 	}
+	uuid, err := c.events(r).Create(&evt)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("ETag", etag(evt.Version))
 	writeKV(w, http.StatusOK, "uuid", uuid)
 }
 
 func (c *Controller) GetEvent(w http.ResponseWriter, r *http.Request) {
 	uuid := mux.Vars(r)["uuid"]
-	evt, err := c.storage.Event.GetByUUID(uuid)
+	evt, err := c.events(r).GetByUUID(uuid)
 	if err != nil {
-		switch err {
-		case sql.ErrNoRows:
-			writeKV(w, http.StatusNotFound, "message", "does not exist")
-		default:
-			writeKV(w, http.StatusInternalServerError, "message", "data access failure")
-		}
-		fmt.Fprint(os.Stderr, err)
+		writeError(w, r, err)
 		return
 	}
+	w.Header().Set("ETag", etag(evt.Version))
+	w.Header().Set("Accept-Patch", acceptPatch)
 	writeJSON(w, http.StatusOK, evt)
 }
 
 func (c *Controller) UpdateEvent(w http.ResponseWriter, r *http.Request) {
 	uuid := mux.Vars(r)["uuid"]
+	version, err := c.ifMatchVersion(r, uuid)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
 	var evt models.Event
-	err := json.NewDecoder(r.Body).Decode(&evt)
+	err = decodeJSON(r, &evt)
 	if err != nil {
-		writeKV(w, http.StatusBadRequest, "message", err.Error())
+		writeMalformedBody(w, r, err)
+		return
+	}
+	evt.Normalize()
+	err = c.rules.Event(&evt)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
-	evt.DateFrom = evt.DateFrom.UTC()
-	evt.DateTo = evt.DateTo.UTC()
 	evt.UUID = uuid
-	err = c.storage.Event.Update(&evt)
+	evt.Version = version
+	err = c.events(r).Update(&evt)
 	if err != nil {
-		writeKV(w, http.StatusInternalServerError, "message", "data access failure")
-		fmt.Fprint(os.Stderr, err)
+		writeError(w, r, err)
 		return
 	}
+	w.Header().Set("ETag", etag(evt.Version))
 	writeKV(w, http.StatusOK, "message", "success")
 }
 
+func (c *Controller) PatchEvent(w http.ResponseWriter, r *http.Request) {
+	uuid := mux.Vars(r)["uuid"]
+	version, err := c.ifMatchVersion(r, uuid)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	current, err := c.events(r).GetByUUID(uuid)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	// The patch is validated against the stored event, so the write must not
+	// go through if the event has changed in between.
+	if version == 0 {
+		version = current.Version
+	}
+
+	doc, err := toDoc(current)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/merge-patch+json", "application/json":
+		var mergePatch any
+		err = json.NewDecoder(r.Body).Decode(&mergePatch)
+		if err != nil {
+			writeMalformedBody(w, r, err)
+			return
+		}
+		doc = patch.Merge(doc, mergePatch)
+	case "application/json-patch+json":
+		var ops []patch.Operation
+		err = decodeJSON(r, &ops)
+		if err != nil {
+			writeMalformedBody(w, r, err)
+			return
+		}
+		doc, err = patch.Apply(doc, ops)
+		if err != nil {
+			WriteProblem(w, r, http.StatusUnprocessableEntity, codePatchFailed, err.Error())
+			return
+		}
+	default:
+		w.Header().Set("Accept-Patch", acceptPatch)
+		WriteProblem(w, r, http.StatusUnsupportedMediaType, codeUnsupportedMedia, fmt.Sprintf("content type %q not supported", mediaType))
+		return
+	}
+
+	var evt models.Event
+	err = fromDoc(doc, &evt)
+	if err != nil {
+		WriteProblem(w, r, http.StatusUnprocessableEntity, codePatchFailed, err.Error())
+		return
+	}
+	evt.Normalize()
+	fields, err := patchedFields(current, &evt)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	err = c.rules.Event(&evt)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	evt.Version = version
+	err = c.events(r).Patch(&evt, fields)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("ETag", etag(evt.Version))
+	writeJSON(w, http.StatusOK, evt)
+}
+
 func (c *Controller) DeleteEvent(w http.ResponseWriter, r *http.Request) {
 	uuid := mux.Vars(r)["uuid"]
-	err := c.storage.Event.Delete(uuid)
+	version, err := c.ifMatchVersion(r, uuid)
 	if err != nil {
-		writeKV(w, http.StatusInternalServerError, "message", "data access failure")
-		fmt.Fprint(os.Stderr, err)
+		writeError(w, r, err)
 		return
 	}
-	writeKV(w, http.StatusOK, "message", "success")
+	err = c.events(r).Delete(uuid, version)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeKVs(w, http.StatusOK, "message", "success", "restore", fmt.Sprintf("/api/events/%s/restore", uuid))
+}
+
+func (c *Controller) GetTrash(w http.ResponseWriter, r *http.Request) {
+	evts, err := c.events(r).GetTrash()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, evts)
+}
+
+func (c *Controller) RestoreEvent(w http.ResponseWriter, r *http.Request) {
+	uuid := mux.Vars(r)["uuid"]
+	evt, err := c.events(r).Restore(uuid)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("ETag", etag(evt.Version))
+	writeJSON(w, http.StatusOK, evt)
+}
+
+// ifMatchVersion returns the event version a write has to be conditional on
+// according to the If-Match header, 0 for an unconditional write or -1 if none
+// of the listed entity tags can ever match. Weak tags count like strong ones:
+// the compression weakens the tags of the responses it encodes, but they still
+// name the version.
+func (c *Controller) ifMatchVersion(r *http.Request, uuid string) (int, error) {
+	header := strings.Join(r.Header.Values("If-Match"), ",")
+	if strings.TrimSpace(header) == "" {
+		if c.config.GetBool("events.require_if_match") {
+			return 0, errPreconditionRequired
+		}
+		return 0, nil
+	}
+	if strings.TrimSpace(header) == "*" {
+		return 0, nil
+	}
+
+	var versions []int
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		v, err := strconv.Atoi(strings.Trim(tag, `"`))
+		if err == nil && v > 0 {
+			versions = append(versions, v)
+		}
+	}
+	switch len(versions) {
+	case 0:
+		return -1, nil
+	case 1:
+		return versions[0], nil
+	}
+
+	evt, err := c.events(r).GetByUUID(uuid)
+	if err != nil {
+		return 0, err
+	}
+	for _, v := range versions {
+		if v == evt.Version {
+			return v, nil
+		}
+	}
+	return -1, nil
+}
+
+func toDoc(evt *models.Event) (any, error) {
+	b, err := json.Marshal(evt)
+	if err != nil {
+		return nil, err
+	}
+	var doc any
+	err = json.Unmarshal(b, &doc)
+	return doc, err
+}
+
+func fromDoc(doc any, evt *models.Event) error {
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	return dec.Decode(evt)
+}
+
+// patchedFields lists the fields in which patched differs from current and
+// rejects changes to fields that are maintained by the storage.
+func patchedFields(current, patched *models.Event) ([]models.EventField, error) {
+	var (
+		fields []models.EventField
+		errs   validation.Errors
+	)
+	readOnly := func(field string, changed bool) {
+		if changed {
+			errs = append(errs, validation.FieldError{Field: field, Code: "read_only", Message: "cannot be changed"})
+		}
+	}
+	readOnly("id", patched.ID != current.ID)
+	readOnly("uuid", patched.UUID != current.UUID)
+	readOnly("created_at", !patched.CreatedAt.Equal(current.CreatedAt))
+	readOnly("updated_at", !patched.UpdatedAt.Equal(current.UpdatedAt))
+	readOnly("version", patched.Version != current.Version)
+	readOnly("deleted_at", !equalTimes(patched.DeletedAt, current.DeletedAt))
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	if patched.Title != current.Title {
+		fields = append(fields, models.Title)
+	}
+	if patched.Description != current.Description {
+		fields = append(fields, models.Description)
+	}
+	if !patched.DateFrom.Equal(current.DateFrom) {
+		fields = append(fields, models.DateFrom)
+	}
+	if !patched.DateTo.Equal(current.DateTo) {
+		fields = append(fields, models.DateTo)
+	}
+	if patched.TZFrom != current.TZFrom {
+		fields = append(fields, models.TZFrom)
+	}
+	if patched.TZTo != current.TZTo {
+		fields = append(fields, models.TZTo)
+	}
+	if !reflect.DeepEqual(patched.Location, current.Location) {
+		fields = append(fields, models.EventLocation)
+	}
+	if patched.URL != current.URL {
+		fields = append(fields, models.URL)
+	}
+	if !reflect.DeepEqual(patched.Conference, current.Conference) {
+		fields = append(fields, models.EventConference)
+	}
+	if !equalTags(patched.Tags, current.Tags) {
+		fields = append(fields, models.Tags)
+	}
+	if patched.AllDay != current.AllDay {
+		fields = append(fields, models.AllDay)
+	}
+	if !equalDates(patched.StartDate, current.StartDate) {
+		fields = append(fields, models.StartDate)
+	}
+	if !equalDates(patched.EndDate, current.EndDate) {
+		fields = append(fields, models.EndDate)
+	}
+	return fields, nil
+}
+
+func equalDates(a, b *models.Date) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func equalTimes(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// equalTags reports whether a and b hold the same tag IDs in any order.
+func equalTags(a, b []models.Tag) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	ids := map[int]bool{}
+	for _, tag := range a {
+		ids[tag.ID] = true
+	}
+	for _, tag := range b {
+		if !ids[tag.ID] {
+			return false
+		}
+	}
+	return true
+}
+
+// withTagParams narrows where down to events with any of the tags named by
+// the tag query parameters.
+func withTagParams(vars url.Values, where filter.Expr) filter.Expr {
+	var tags filter.Expr
+	for _, name := range vars["tag"] {
+		var cmp filter.Expr = &filter.Comparison{Field: filter.Tag, Op: filter.Eq, Value: name}
+		if tags != nil {
+			cmp = &filter.Or{Left: tags, Right: cmp}
+		}
+		tags = cmp
+	}
+	switch {
+	case tags == nil:
+		return where
+	case where == nil:
+		return tags
+	}
+	return &filter.And{Left: where, Right: tags}
+}
+
+func etag(version int) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// notModified sets an ETag for the events in the window and answers 304 if it
+// matches If-None-Match. The tag covers the request URL, so different sorts,
+// limits and filters of the same window never share one. It reports whether a
+// response was written.
+func (c *Controller) notModified(w http.ResponseWriter, r *http.Request, startDate, endDate time.Time, where filter.Expr) bool {
+	stat, err := c.events(r).Stat(startDate, endDate, where)
+	if err != nil {
+		writeError(w, r, err)
+		return true
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%s?%s\n%d\n%d\n%s\n%s", r.URL.Path, r.URL.RawQuery, stat.Count, stat.UpdatedAt.UnixNano(), stat.Events, stat.Tags)
+	tag := fmt.Sprintf(`"%x"`, h.Sum(nil)[:16])
+	w.Header().Set("ETag", tag)
+	if !etagMatches(r.Header.Values("If-None-Match"), tag) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatches reports whether tag matches one of the If-None-Match values, which
+// are compared weakly as RFC 9110 requires for GET.
+func etagMatches(values []string, tag string) bool {
+	for _, value := range values {
+		for _, t := range strings.Split(value, ",") {
+			t = strings.TrimSpace(t)
+			if t == "*" || strings.TrimPrefix(t, "W/") == tag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func parseWindow(vars url.Values) (startDate, endDate time.Time, err error) {
+	if vars.Has("start") {
+		startDate, err = time.Parse(time.RFC3339, vars.Get("start"))
+		if err != nil {
+			return
+		}
+		startDate = startDate.UTC()
+	}
+	if vars.Has("end") {
+		endDate, err = time.Parse(time.RFC3339, vars.Get("end"))
+		if err != nil {
+			return
+		}
+		endDate = endDate.UTC()
+	}
+	return
 }
@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"api/internal/filter"
+	"api/internal/ics"
+	"api/internal/models"
+	"net/http"
+	"time"
+)
+
+// ExportEvents returns the events in the start/end window as an iCalendar
+// file. It takes the same filter and tag parameters as GetEvents.
+func (c *Controller) ExportEvents(w http.ResponseWriter, r *http.Request) {
+	vars := r.URL.Query()
+	startDate, endDate, err := parseWindow(vars)
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	var where filter.Expr
+	if vars.Has("filter") {
+		where, err = filter.Parse(vars.Get("filter"))
+		if err != nil {
+			writeBadRequest(w, r, err)
+			return
+		}
+	}
+	where = withTagParams(vars, where)
+
+	evts, err := c.events(r).GetByFilter(startDate, endDate, where, models.DateFrom, models.Asc, 0)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", ics.ContentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="events.ics"`)
+	if err := ics.Write(w, evts, time.Now()); err != nil {
+		logError(r, "write calendar", err)
+	}
+}
@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"api/internal/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+func (c *Controller) GetTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := c.storage.Tag.GetAll()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tags)
+}
+
+func (c *Controller) GetTag(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	tag, err := c.storage.Tag.GetByID(id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tag)
+}
+
+func (c *Controller) CreateTag(w http.ResponseWriter, r *http.Request) {
+	var tag models.Tag
+	err := decodeJSON(r, &tag)
+	if err != nil {
+		writeMalformedBody(w, r, err)
+		return
+	}
+	err = c.rules.Tag(&tag)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	err = c.storage.Tag.Create(&tag)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tag)
+}
+
+func (c *Controller) UpdateTag(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	var tag models.Tag
+	err = decodeJSON(r, &tag)
+	if err != nil {
+		writeMalformedBody(w, r, err)
+		return
+	}
+	err = c.rules.Tag(&tag)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	tag.ID = id
+	err = c.storage.Tag.Update(&tag)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tag)
+}
+
+func (c *Controller) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	err = c.storage.Tag.Delete(id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeKV(w, http.StatusOK, "message", "success")
+}
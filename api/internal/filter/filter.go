@@ -0,0 +1,167 @@
+// Package filter parses event filter expressions such as
+//
+//	title~"standup" and duration>=30m and created_at>2024-01-01
+//
+// into an AST that storage backends compile into their own query language.
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+type Kind int
+
+const (
+	String Kind = iota
+	Number
+	Time
+	Duration
+)
+
+func (k Kind) String() string {
+	switch k {
+	case String:
+		return "string"
+	case Number:
+		return "number"
+	case Time:
+		return "time"
+	case Duration:
+		return "duration"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+type Field string
+
+const (
+	ID          Field = "id"
+	UUID        Field = "uuid"
+	Title       Field = "title"
+	Description Field = "description"
+	DateFrom    Field = "date_from"
+	DateTo      Field = "date_to"
+	TZFrom      Field = "tz_from"
+	TZTo        Field = "tz_to"
+	URL         Field = "url"
+	CreatedAt   Field = "created_at"
+	UpdatedAt   Field = "updated_at"
+	Version     Field = "version"
+	// Name of any of the tags of an event.
+	Tag Field = "tag"
+	// Name of the location of an event.
+	EventLocation Field = "location"
+	// Duration of an event, i.e. date_to - date_from.
+	EventDuration Field = "duration"
+)
+
+var fieldKinds = map[Field]Kind{
+	ID:            Number,
+	UUID:          String,
+	Title:         String,
+	Description:   String,
+	DateFrom:      Time,
+	DateTo:        Time,
+	TZFrom:        String,
+	TZTo:          String,
+	URL:           String,
+	CreatedAt:     Time,
+	UpdatedAt:     Time,
+	Version:       Number,
+	Tag:           String,
+	EventLocation: String,
+	EventDuration: Duration,
+}
+
+func (f Field) Kind() Kind {
+	return fieldKinds[f]
+}
+
+type Op string
+
+const (
+	Eq          Op = "="
+	Ne          Op = "!="
+	Lt          Op = "<"
+	Le          Op = "<="
+	Gt          Op = ">"
+	Ge          Op = ">="
+	Contains    Op = "~"
+	NotContains Op = "!~"
+)
+
+var kindOps = map[Kind][]Op{
+	String:   {Eq, Ne, Contains, NotContains},
+	Number:   {Eq, Ne, Lt, Le, Gt, Ge},
+	Time:     {Eq, Ne, Lt, Le, Gt, Ge},
+	Duration: {Eq, Ne, Lt, Le, Gt, Ge},
+}
+
+type Expr interface {
+	expr()
+}
+
+type And struct {
+	Left, Right Expr
+}
+
+type Or struct {
+	Left, Right Expr
+}
+
+type Not struct {
+	X Expr
+}
+
+// Comparison compares Field against Value, which holds a string, int64,
+// time.Time or time.Duration according to the kind of Field.
+type Comparison struct {
+	Field Field
+	Op    Op
+	Value any
+}
+
+func (*And) expr()        {}
+func (*Or) expr()         {}
+func (*Not) expr()        {}
+func (*Comparison) expr() {}
+
+type Error struct {
+	Offset int
+	Token  string
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	if e.Token == "" {
+		return fmt.Sprintf("filter: %s at end of input", e.Msg)
+	}
+	return fmt.Sprintf("filter: %s at column %d near %q", e.Msg, e.Offset+1, e.Token)
+}
+
+func parseValue(kind Kind, s string) (any, bool) {
+	switch kind {
+	case Number:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, false
+		}
+		return n, true
+	case Time:
+		for _, layout := range []string{time.RFC3339, time.DateTime, time.DateOnly} {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t.UTC(), true
+			}
+		}
+		return nil, false
+	case Duration:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, false
+		}
+		return d, true
+	}
+	return s, true
+}
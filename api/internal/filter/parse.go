@@ -0,0 +1,238 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenType int
+
+const (
+	tokEOF tokenType = iota
+	tokWord
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	typ    tokenType
+	text   string
+	value  string
+	offset int
+}
+
+// Parse parses the filter expression s. Comparisons bind tighter than
+// "not", which binds tighter than "and", which binds tighter than "or".
+// Parse returns an *Error for malformed input.
+func Parse(s string) (Expr, error) {
+	toks, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.typ != tokEOF {
+		return nil, p.errorf(tok, "unexpected token")
+	}
+	return expr, nil
+}
+
+func lex(s string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '(':
+			toks = append(toks, token{typ: tokLParen, text: "(", offset: i})
+			i++
+		case r == ')':
+			toks = append(toks, token{typ: tokRParen, text: ")", offset: i})
+			i++
+		case r == '"':
+			tok, err := lexString(s, i)
+			if err != nil {
+				return nil, err
+			}
+			toks = append(toks, tok)
+			i += len(tok.text)
+		case strings.ContainsRune("=!<>~", r):
+			op := s[i : i+1]
+			if i+1 < len(s) {
+				switch two := s[i : i+2]; two {
+				case "!=", "<=", ">=", "!~":
+					op = two
+				}
+			}
+			if op == "!" {
+				return nil, &Error{Offset: i, Token: op, Msg: "unknown operator"}
+			}
+			toks = append(toks, token{typ: tokOp, text: op, offset: i})
+			i += len(op)
+		default:
+			j := i
+			for j < len(s) {
+				r, size := utf8.DecodeRuneInString(s[j:])
+				if unicode.IsSpace(r) || strings.ContainsRune(`()"=!<>~`, r) {
+					break
+				}
+				j += size
+			}
+			toks = append(toks, token{typ: tokWord, text: s[i:j], value: s[i:j], offset: i})
+			i = j
+		}
+	}
+	return append(toks, token{typ: tokEOF, offset: len(s)}), nil
+}
+
+func lexString(s string, start int) (token, error) {
+	var b strings.Builder
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 == len(s) {
+				break
+			}
+			i++
+			b.WriteByte(s[i])
+		case '"':
+			return token{typ: tokString, text: s[start : i+1], value: b.String(), offset: start}, nil
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return token{}, &Error{Offset: start, Token: s[start:], Msg: "unterminated string"}
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.toks[p.pos]
+	if tok.typ != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) keyword(kw string) bool {
+	tok := p.peek()
+	if tok.typ == tokWord && strings.EqualFold(tok.text, kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) errorf(tok token, format string, args ...any) *Error {
+	return &Error{Offset: tok.offset, Token: tok.text, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Or{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &And{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (Expr, error) {
+	if p.keyword("not") {
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{X: x}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	tok := p.next()
+	switch tok.typ {
+	case tokLParen:
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.typ != tokRParen {
+			return nil, p.errorf(closing, "expected )")
+		}
+		return expr, nil
+	case tokWord:
+		return p.parseComparison(tok)
+	}
+	return nil, p.errorf(tok, "expected field name or (")
+}
+
+func (p *parser) parseComparison(fieldTok token) (Expr, error) {
+	field := Field(strings.ToLower(fieldTok.text))
+	kind, ok := fieldKinds[field]
+	if !ok {
+		return nil, p.errorf(fieldTok, "unknown field")
+	}
+
+	opTok := p.next()
+	if opTok.typ != tokOp {
+		return nil, p.errorf(opTok, "expected operator")
+	}
+	op := Op(opTok.text)
+	if !supports(kind, op) {
+		return nil, p.errorf(opTok, "operator not supported for %s field %s", kind, field)
+	}
+
+	valTok := p.next()
+	if valTok.typ != tokWord && valTok.typ != tokString {
+		return nil, p.errorf(valTok, "expected value")
+	}
+	value, ok := parseValue(kind, valTok.value)
+	if !ok {
+		return nil, p.errorf(valTok, "invalid %s value", kind)
+	}
+	return &Comparison{Field: field, Op: op, Value: value}, nil
+}
+
+func supports(kind Kind, op Op) bool {
+	for _, o := range kindOps[kind] {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
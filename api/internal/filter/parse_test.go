@@ -0,0 +1,107 @@
+package filter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  Expr
+	}{
+		{
+			name:  "Contains",
+			input: `title~"standup"`,
+			want:  &Comparison{Field: Title, Op: Contains, Value: "standup"},
+		},
+		{
+			name:  "Escaped Quote",
+			input: `description = "say \"hi\""`,
+			want:  &Comparison{Field: Description, Op: Eq, Value: `say "hi"`},
+		},
+		{
+			name:  "Number",
+			input: `id != 3`,
+			want:  &Comparison{Field: ID, Op: Ne, Value: int64(3)},
+		},
+		{
+			name:  "Duration",
+			input: `duration>=1h30m`,
+			want:  &Comparison{Field: EventDuration, Op: Ge, Value: 90 * time.Minute},
+		},
+		{
+			name:  "Date",
+			input: `created_at>2024-01-01`,
+			want:  &Comparison{Field: CreatedAt, Op: Gt, Value: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:  "Timestamp With Offset",
+			input: `date_from <= 2024-01-01T10:00:00+02:00`,
+			want:  &Comparison{Field: DateFrom, Op: Le, Value: time.Date(2024, time.January, 1, 8, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:  "Precedence",
+			input: `title~"a" or title~"b" and not duration<30m`,
+			want: &Or{
+				Left: &Comparison{Field: Title, Op: Contains, Value: "a"},
+				Right: &And{
+					Left:  &Comparison{Field: Title, Op: Contains, Value: "b"},
+					Right: &Not{X: &Comparison{Field: EventDuration, Op: Lt, Value: 30 * time.Minute}},
+				},
+			},
+		},
+		{
+			name:  "Parentheses",
+			input: `(title!~"a" OR uuid="x") AND id>1`,
+			want: &And{
+				Left: &Or{
+					Left:  &Comparison{Field: Title, Op: NotContains, Value: "a"},
+					Right: &Comparison{Field: UUID, Op: Eq, Value: "x"},
+				},
+				Right: &Comparison{Field: ID, Op: Gt, Value: int64(1)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		offset int
+		token  string
+	}{
+		{"Unknown Field", `title~"a" and colour="red"`, 14, "colour"},
+		{"Unsupported Operator", `duration~30m`, 8, "~"},
+		{"Invalid Value", `created_at > yesterday`, 13, "yesterday"},
+		{"Missing Value", `title =`, 7, ""},
+		{"Missing Operator", `title "a"`, 6, `"a"`},
+		{"Unterminated String", `title = "abc`, 8, `"abc`},
+		{"Unbalanced Parenthesis", `(id = 1`, 7, ""},
+		{"Trailing Token", `id = 1 id = 2`, 7, "id"},
+		{"Unknown Operator", `id ! 1`, 3, "!"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			var ferr *Error
+			if assert.ErrorAs(t, err, &ferr) {
+				assert.Equal(t, tt.offset, ferr.Offset)
+				assert.Equal(t, tt.token, ferr.Token)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+// Package ics writes events as an iCalendar (RFC 5545) calendar, with the
+// conferencing properties of RFC 7986.
+package ics
+
+import (
+	"api/internal/models"
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	ContentType = "text/calendar; charset=utf-8"
+
+	prodID = "-//calendar//events//EN"
+	// maxLine is the length in octets after which lines are folded.
+	maxLine = 75
+
+	utcFormat = "20060102T150405Z"
+)
+
+// Write writes evts as a VCALENDAR. now is the DTSTAMP of all events.
+func Write(w io.Writer, evts []models.Event, now time.Time) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		fold(bw, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", prodID)
+	line("CALSCALE", "GREGORIAN")
+	for i := range evts {
+		evt := &evts[i]
+		line("BEGIN", "VEVENT")
+		line("UID", evt.UUID)
+		line("DTSTAMP", now.UTC().Format(utcFormat))
+		if evt.AllDay && evt.StartDate != nil && evt.EndDate != nil {
+			// DTEND of a date is exclusive.
+			line("DTSTART;VALUE=DATE", formatDate(*evt.StartDate))
+			line("DTEND;VALUE=DATE", formatDate(evt.EndDate.AddDays(1)))
+		} else {
+			line("DTSTART", evt.DateFrom.UTC().Format(utcFormat))
+			line("DTEND", evt.DateTo.UTC().Format(utcFormat))
+		}
+		line("SUMMARY", escape(evt.Title))
+		if evt.Description != "" {
+			line("DESCRIPTION", escape(evt.Description))
+		}
+		if loc := evt.Location; loc != nil {
+			if loc.Name != "" {
+				line("LOCATION", escape(loc.Name))
+			}
+			if loc.Latitude != nil && loc.Longitude != nil {
+				line("GEO", formatFloat(*loc.Latitude)+";"+formatFloat(*loc.Longitude))
+			}
+		}
+		if evt.URL != "" {
+			line("URL;VALUE=URI", evt.URL)
+		}
+		if conf := evt.Conference; conf != nil {
+			if conf.JoinURL != "" {
+				params := ";VALUE=URI;FEATURE=AUDIO,VIDEO"
+				if conf.Provider != "" {
+					params += ";LABEL=" + quoteParam(conf.Provider)
+				}
+				line("CONFERENCE"+params, conf.JoinURL)
+			}
+			if conf.DialIn != "" {
+				line("CONFERENCE;VALUE=URI;FEATURE=PHONE", telURI(conf.DialIn))
+			}
+		}
+		if len(evt.Tags) > 0 {
+			names := make([]string, len(evt.Tags))
+			for i, tag := range evt.Tags {
+				names[i] = escape(tag.Name)
+			}
+			line("CATEGORIES", strings.Join(names, ","))
+		}
+		if !evt.CreatedAt.IsZero() {
+			line("CREATED", evt.CreatedAt.UTC().Format(utcFormat))
+		}
+		if !evt.UpdatedAt.IsZero() {
+			line("LAST-MODIFIED", evt.UpdatedAt.UTC().Format(utcFormat))
+		}
+		line("SEQUENCE", strconv.Itoa(evt.Version))
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// fold writes s as a content line, split into lines of at most maxLine octets
+// that continue with a space. Lines are only split between characters.
+func fold(w *bufio.Writer, s string) {
+	limit := maxLine
+	for len(s) > limit {
+		i := limit
+		for i > 0 && !utf8.RuneStart(s[i]) {
+			i--
+		}
+		w.WriteString(s[:i])
+		w.WriteString("\r\n ")
+		s = s[i:]
+		// The leading space counts towards the length.
+		limit = maxLine - 1
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+// escape escapes a TEXT value.
+func escape(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// quoteParam quotes a parameter value. Double quotes cannot be escaped, so
+// they are dropped.
+func quoteParam(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r == '"' || r < ' ' {
+			return -1
+		}
+		return r
+	}, s)
+	return `"` + s + `"`
+}
+
+// telURI makes a tel URI (RFC 3966) of a dial-in number like
+// "+1 555-0100,,4711#". Digits after a comma or an x are the extension.
+// Values that are URIs already are kept.
+func telURI(dialIn string) string {
+	if strings.Contains(dialIn, ":") {
+		return dialIn
+	}
+	number, ext, _ := strings.Cut(strings.TrimSpace(dialIn), ",")
+	if n, e, ok := strings.Cut(number, "x"); ok {
+		number, ext = n, e
+	}
+	uri := "tel:" + keep(number, "+0123456789-")
+	if ext = keep(ext, "0123456789"); ext != "" {
+		uri += ";ext=" + ext
+	}
+	return uri
+}
+
+func keep(s, chars string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(chars, r) {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+func formatDate(d models.Date) string {
+	return fmt.Sprintf("%04d%02d%02d", d.Year, d.Month, d.Day)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
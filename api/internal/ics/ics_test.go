@@ -0,0 +1,85 @@
+package ics
+
+import (
+	"api/internal/models"
+	"bufio"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	lat, long := 52.52, 13.405
+	evts := []models.Event{
+		{
+			UUID:        "123e4567-e89b-12d3-a456-426614174000",
+			Title:       "Planning; Q4, part 1",
+			Description: "Agenda:\nbudget",
+			DateFrom:    time.Date(2023, time.October, 1, 10, 0, 0, 0, time.UTC),
+			DateTo:      time.Date(2023, time.October, 1, 12, 0, 0, 0, time.UTC),
+			Location:    &models.Location{Name: "Room 1", Latitude: &lat, Longitude: &long},
+			URL:         "https://example.com/planning",
+			Conference: &models.Conference{
+				Provider: "Jitsi",
+				JoinURL:  "https://meet.jit.si/planning",
+				DialIn:   "+49 30 1234-5678,,4711#",
+			},
+			Tags:    []models.Tag{{Name: "Internal"}, {Name: "Focus"}},
+			Version: 3,
+		},
+		{
+			UUID:      "123e4567-e89b-12d3-a456-426614174006",
+			Title:     "Company Holiday",
+			AllDay:    true,
+			StartDate: &models.Date{Year: 2023, Month: time.October, Day: 25},
+			EndDate:   &models.Date{Year: 2023, Month: time.October, Day: 26},
+		},
+	}
+	var b strings.Builder
+	err := Write(&b, evts, time.Date(2023, time.September, 1, 9, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	out := strings.ReplaceAll(b.String(), "\r\n ", "")
+
+	for _, line := range []string{
+		"BEGIN:VCALENDAR",
+		"UID:123e4567-e89b-12d3-a456-426614174000",
+		"DTSTAMP:20230901T090000Z",
+		"DTSTART:20231001T100000Z",
+		"DTEND:20231001T120000Z",
+		`SUMMARY:Planning\; Q4\, part 1`,
+		`DESCRIPTION:Agenda:\nbudget`,
+		"LOCATION:Room 1",
+		"GEO:52.52;13.405",
+		"URL;VALUE=URI:https://example.com/planning",
+		`CONFERENCE;VALUE=URI;FEATURE=AUDIO,VIDEO;LABEL="Jitsi":https://meet.jit.si/planning`,
+		"CONFERENCE;VALUE=URI;FEATURE=PHONE:tel:+49301234-5678;ext=4711",
+		"CATEGORIES:Internal,Focus",
+		"SEQUENCE:3",
+		"DTSTART;VALUE=DATE:20231025",
+		"DTEND;VALUE=DATE:20231027",
+		"END:VCALENDAR",
+	} {
+		assert.Contains(t, out, line+"\r\n")
+	}
+	assert.NotContains(t, out, "CONFERENCE;VALUE=URI;FEATURE=PHONE:tel:+49 ")
+}
+
+func TestFold(t *testing.T) {
+	var b strings.Builder
+	w := bufio.NewWriter(&b)
+	fold(w, "DESCRIPTION:"+strings.Repeat("ä", 80))
+	w.Flush()
+
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n")
+	assert.Len(t, lines, 3)
+	for i, line := range lines {
+		assert.LessOrEqual(t, len(line), maxLine)
+		if i > 0 {
+			assert.True(t, strings.HasPrefix(line, " "))
+		}
+	}
+	unfolded := strings.ReplaceAll(strings.TrimSuffix(b.String(), "\r\n"), "\r\n ", "")
+	assert.Equal(t, "DESCRIPTION:"+strings.Repeat("ä", 80), unfolded)
+}
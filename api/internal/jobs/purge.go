@@ -0,0 +1,128 @@
+// Package jobs contains the background workers of the server.
+package jobs
+
+import (
+	"api/internal/blob"
+	"api/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Purger periodically removes events that have been in the trash for longer
+// than the retention period, along with the blobs of their attachments.
+type Purger struct {
+	events      models.EventAccess
+	attachments models.AttachmentAccess
+	blobs       blob.Store
+	retention   time.Duration
+	interval    time.Duration
+	lastRun     atomic.Int64
+}
+
+const (
+	defaultRetention     = 30 * 24 * time.Hour
+	defaultPurgeInterval = time.Hour
+)
+
+// NewPurger creates a purger with trash.retention and trash.purge_interval
+// from config. Both must be positive if set, as a zero retention would purge
+// deleted events before they could be restored.
+func NewPurger(events models.EventAccess, attachments models.AttachmentAccess, blobs blob.Store, config *viper.Viper) (*Purger, error) {
+	p := &Purger{
+		events:      events,
+		attachments: attachments,
+		blobs:       blobs,
+		retention:   defaultRetention,
+		interval:    defaultPurgeInterval,
+	}
+	if config.IsSet("trash.retention") {
+		p.retention = config.GetDuration("trash.retention")
+		if p.retention <= 0 {
+			return nil, fmt.Errorf("trash.retention must be positive, got %q", config.GetString("trash.retention"))
+		}
+	}
+	if config.IsSet("trash.purge_interval") {
+		p.interval = config.GetDuration("trash.purge_interval")
+		if p.interval <= 0 {
+			return nil, fmt.Errorf("trash.purge_interval must be positive, got %q", config.GetString("trash.purge_interval"))
+		}
+	}
+	return p, nil
+}
+
+// Run purges once right away and then every interval until ctx is done.
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		p.purge(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check returns an error if the purger has not purged successfully for two
+// intervals, e.g. because it was stopped, a purge hangs or the database fails.
+func (p *Purger) Check(ctx context.Context) error {
+	last := p.lastRun.Load()
+	if last == 0 {
+		return fmt.Errorf("purger has not run yet")
+	}
+	if since := time.Since(time.Unix(0, last)); since > 2*p.interval {
+		return fmt.Errorf("purger last succeeded %s ago", since.Round(time.Second))
+	}
+	return nil
+}
+
+func (p *Purger) purge(ctx context.Context) {
+	before := time.Now().UTC().Add(-p.retention)
+	keys, err := p.attachments.Trashed(before)
+	if err != nil {
+		slog.Error("list attachments to purge", "error", err)
+		return
+	}
+	n, err := p.events.WithContext(ctx).Purge(before)
+	if err != nil {
+		slog.Error("purge trash", "error", err)
+		return
+	}
+	if n > 0 {
+		slog.Info("purged events from the trash", "count", n)
+	}
+	p.lastRun.Store(time.Now().UnixNano())
+	p.deleteBlobs(ctx, keys)
+}
+
+// deleteBlobs deletes the blobs of those of keys whose attachments are gone.
+// Attachments of events restored while purging are kept.
+func (p *Purger) deleteBlobs(ctx context.Context, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+	existing, err := p.attachments.Existing(keys)
+	if err != nil {
+		slog.Error("check purged attachments", "error", err)
+		return
+	}
+	kept := make(map[string]bool, len(existing))
+	for _, key := range existing {
+		kept[key] = true
+	}
+	for _, key := range keys {
+		if kept[key] {
+			continue
+		}
+		if err := p.blobs.Delete(ctx, key); err != nil && !errors.Is(err, blob.ErrNotFound) {
+			slog.Error("delete blob of purged attachment", "key", key, "error", err)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package jobs
+
+import (
+	"api/internal/models"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPurger(t *testing.T) {
+	p, err := NewPurger(nil, nil, nil, viper.New())
+	if assert.NoError(t, err) {
+		assert.Equal(t, defaultRetention, p.retention)
+		assert.Equal(t, defaultPurgeInterval, p.interval)
+	}
+
+	for _, key := range []string{"trash.retention", "trash.purge_interval"} {
+		for _, value := range []string{"0", "-1h"} {
+			config := viper.New()
+			config.Set(key, value)
+			_, err := NewPurger(nil, nil, nil, config)
+			assert.Error(t, err, "%s: %s", key, value)
+		}
+	}
+
+	config := viper.New()
+	config.Set("trash.retention", "48h")
+	config.Set("trash.purge_interval", "10m")
+	p, err = NewPurger(nil, nil, nil, config)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 48*time.Hour, p.retention)
+		assert.Equal(t, 10*time.Minute, p.interval)
+	}
+}
+
+type fakeEvents struct {
+	models.EventAccess
+	err error
+}
+
+func (f *fakeEvents) WithContext(ctx context.Context) models.EventAccess {
+	return f
+}
+
+func (f *fakeEvents) Purge(before time.Time) (int64, error) {
+	return 0, f.err
+}
+
+type fakeAttachments struct {
+	models.AttachmentAccess
+}
+
+func (fakeAttachments) Trashed(before time.Time) ([]string, error) {
+	return nil, nil
+}
+
+func TestPurgerCheck(t *testing.T) {
+	events := &fakeEvents{err: errors.New("connection refused")}
+	p, err := NewPurger(events, fakeAttachments{}, nil, viper.New())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	p.purge(context.Background())
+	assert.Error(t, p.Check(context.Background()), "failed purge")
+
+	events.err = nil
+	p.purge(context.Background())
+	assert.NoError(t, p.Check(context.Background()))
+
+	p.lastRun.Store(time.Now().Add(-3 * defaultPurgeInterval).UnixNano())
+	assert.Error(t, p.Check(context.Background()), "stale")
+}
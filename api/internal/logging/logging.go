@@ -0,0 +1,110 @@
+// Package logging sets up structured logging and attaches a logger carrying
+// the request ID to every request.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/felixge/httpsnoop"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	RequestIDHeader       = "X-Request-ID"
+	maxRequestIDLength    = 128
+	defaultAccessLogLevel = slog.LevelInfo
+)
+
+type contextKey struct{}
+
+// New creates a logger writing to w at the level of log.level, as JSON or,
+// if log.format is "text", as key=value pairs.
+func New(w io.Writer, config *viper.Viper) (*slog.Logger, error) {
+	var level slog.Level
+	if config.IsSet("log.level") {
+		if err := level.UnmarshalText([]byte(config.GetString("log.level"))); err != nil {
+			return nil, fmt.Errorf("log.level: %w", err)
+		}
+	}
+	opts := &slog.HandlerOptions{Level: level}
+	switch format := config.GetString("log.format"); format {
+	case "", "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	case "text":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("log.format %q not supported", format)
+	}
+}
+
+// FromContext returns the logger of the request ctx belongs to, or the
+// default logger. It adds the trace ID if ctx is part of a trace.
+func FromContext(ctx context.Context) *slog.Logger {
+	logger, ok := ctx.Value(contextKey{}).(*slog.Logger)
+	if !ok {
+		logger = slog.Default()
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		logger = logger.With("trace_id", sc.TraceID().String())
+	}
+	return logger
+}
+
+// RequestID tags each request with the ID from its X-Request-ID header, or a
+// new one if it has none, and echoes it in the response.
+func RequestID(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.New().String()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		logger := slog.Default().With("request_id", id)
+		h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, logger)))
+	})
+}
+
+// validRequestID accepts IDs of printable ASCII so that a client cannot
+// inject control characters into the logs.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// AccessLog logs each request once it has been answered, except for the
+// paths in skip.
+func AccessLog(skip map[string]bool) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if skip[r.URL.Path] {
+				h.ServeHTTP(w, r)
+				return
+			}
+			start := time.Now()
+			m := httpsnoop.CaptureMetrics(h, w, r)
+			FromContext(r.Context()).LogAttrs(r.Context(), defaultAccessLogLevel, "request",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", m.Code),
+				slog.Int64("bytes", m.Written),
+				slog.Duration("duration", time.Since(start)),
+				slog.String("remote_addr", r.RemoteAddr),
+				slog.String("user_agent", r.UserAgent()),
+			)
+		})
+	}
+}
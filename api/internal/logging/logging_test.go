@@ -0,0 +1,81 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	config := viper.New()
+	config.Set("log.level", "warn")
+	var buf bytes.Buffer
+	logger, err := New(&buf, config)
+	assert.NoError(t, err)
+	logger.Info("hidden")
+	logger.Warn("shown", "n", 1)
+	var line map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "shown", line["msg"])
+	assert.Equal(t, "WARN", line["level"])
+
+	config.Set("log.level", "loud")
+	_, err = New(&buf, config)
+	assert.Error(t, err)
+}
+
+func TestRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	h := RequestID(AccessLog(map[string]bool{"/healthz": true})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})))
+
+	tests := []struct {
+		name   string
+		path   string
+		header string
+		keep   bool
+		logged bool
+	}{
+		{"Generated", "/api/events", "", false, true},
+		{"Propagated", "/api/events", "abc-123", true, true},
+		{"Control Characters Replaced", "/api/events", "abc\n{\"forged\":1}", false, true},
+		{"Too Long Replaced", "/api/events", strings.Repeat("x", 129), false, true},
+		{"Skipped", "/healthz", "abc-123", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set(RequestIDHeader, tt.header)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			id := w.Header().Get(RequestIDHeader)
+			assert.NotEmpty(t, id)
+			if tt.keep {
+				assert.Equal(t, tt.header, id)
+			} else {
+				assert.NotEqual(t, tt.header, id)
+			}
+			if !tt.logged {
+				assert.Zero(t, buf.Len())
+				return
+			}
+			var line map[string]any
+			assert.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+			assert.Equal(t, id, line["request_id"])
+			assert.Equal(t, float64(http.StatusTeapot), line["status"])
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"api/internal/models"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouter(t *testing.T) {
+	m := New()
+	r := mux.NewRouter()
+	r.HandleFunc("/api/events/{uuid}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods(http.MethodGet)
+	h := m.Router(r)
+
+	for _, uuid := range []string{"a", "b", "c"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/events/"+uuid, nil))
+	}
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/nothing", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/events/a", nil))
+
+	assert.Equal(t, 3.0, testutil.ToFloat64(m.requests.WithLabelValues("/api/events/{uuid}", "GET", "404")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues("unmatched", "GET", "404")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues("unmatched", "POST", "405")))
+	assert.Equal(t, 3, testutil.CollectAndCount(m.requestDuration))
+}
+
+type fakeEventAccess struct {
+	models.EventAccess
+	err error
+}
+
+func (fe *fakeEventAccess) GetAll() ([]models.Event, error) {
+	return []models.Event{{ID: 1}, {ID: 2}}, fe.err
+}
+
+func (fe *fakeEventAccess) GetByUUID(uuid string) (*models.Event, error) {
+	return nil, fe.err
+}
+
+func (fe *fakeEventAccess) InTx(fn func(tx models.EventAccess) error) error {
+	return fn(fe)
+}
+
+func TestEventAccess(t *testing.T) {
+	m := New()
+	fake := &fakeEventAccess{}
+	ea := m.EventAccess(fake)
+
+	evts, err := ea.GetAll()
+	assert.NoError(t, err)
+	assert.Len(t, evts, 2)
+
+	fake.err = &models.StorageError{Kind: models.ErrNotFound}
+	_, err = ea.GetByUUID("x")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	fake.err = errors.New("connection reset")
+	err = ea.InTx(func(tx models.EventAccess) error {
+		_, err := tx.GetByUUID("y")
+		return err
+	})
+	assert.Error(t, err)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.storageErrors.WithLabelValues("GetByUUID", "not_found")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.storageErrors.WithLabelValues("GetByUUID", "other")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.storageErrors.WithLabelValues("InTx", "other")))
+
+	expected := `
+# HELP calendar_storage_rows_returned Rows returned or affected by event storage calls by method.
+# TYPE calendar_storage_rows_returned histogram
+calendar_storage_rows_returned_bucket{method="GetAll",le="0"} 0
+calendar_storage_rows_returned_bucket{method="GetAll",le="1"} 0
+calendar_storage_rows_returned_bucket{method="GetAll",le="5"} 1
+calendar_storage_rows_returned_bucket{method="GetAll",le="10"} 1
+calendar_storage_rows_returned_bucket{method="GetAll",le="50"} 1
+calendar_storage_rows_returned_bucket{method="GetAll",le="100"} 1
+calendar_storage_rows_returned_bucket{method="GetAll",le="500"} 1
+calendar_storage_rows_returned_bucket{method="GetAll",le="1000"} 1
+calendar_storage_rows_returned_bucket{method="GetAll",le="5000"} 1
+calendar_storage_rows_returned_bucket{method="GetAll",le="+Inf"} 1
+calendar_storage_rows_returned_sum{method="GetAll"} 2
+calendar_storage_rows_returned_count{method="GetAll"} 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(m.storageRows, strings.NewReader(expected)))
+}
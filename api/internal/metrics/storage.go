@@ -0,0 +1,156 @@
+package metrics
+
+import (
+	"api/internal/filter"
+	"api/internal/models"
+	"context"
+	"errors"
+	"time"
+)
+
+type eventAccess struct {
+	next    models.EventAccess
+	metrics *Metrics
+}
+
+// EventAccess wraps next so that every call is timed and its errors and
+// returned rows are counted.
+func (m *Metrics) EventAccess(next models.EventAccess) models.EventAccess {
+	return &eventAccess{next: next, metrics: m}
+}
+
+// observe records a call to method that started at start. rows is negative
+// for calls that do not return rows.
+func (ea *eventAccess) observe(method string, start time.Time, rows int, err error) {
+	ea.metrics.storageDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if err != nil {
+		ea.metrics.storageErrors.WithLabelValues(method, errorKind(err)).Inc()
+		return
+	}
+	if rows >= 0 {
+		ea.metrics.storageRows.WithLabelValues(method).Observe(float64(rows))
+	}
+}
+
+func errorKind(err error) string {
+	for _, kind := range []struct {
+		err  error
+		name string
+	}{
+		{models.ErrNotFound, "not_found"},
+		{models.ErrConflict, "conflict"},
+		{models.ErrValidation, "validation"},
+		{models.ErrConstraint, "constraint"},
+		{models.ErrPrecondition, "precondition"},
+	} {
+		if errors.Is(err, kind.err) {
+			return kind.name
+		}
+	}
+	return "other"
+}
+
+func (ea *eventAccess) GetAll() ([]models.Event, error) {
+	start := time.Now()
+	evts, err := ea.next.GetAll()
+	ea.observe("GetAll", start, len(evts), err)
+	return evts, err
+}
+
+func (ea *eventAccess) GetByFilter(
+	startDate, endDate time.Time,
+	where filter.Expr,
+	sortField models.EventField,
+	sortOrder models.SortOrder,
+	limit int,
+) ([]models.Event, error) {
+	start := time.Now()
+	evts, err := ea.next.GetByFilter(startDate, endDate, where, sortField, sortOrder, limit)
+	ea.observe("GetByFilter", start, len(evts), err)
+	return evts, err
+}
+
+func (ea *eventAccess) Stat(startDate, endDate time.Time, where filter.Expr) (models.ListStat, error) {
+	start := time.Now()
+	stat, err := ea.next.Stat(startDate, endDate, where)
+	ea.observe("Stat", start, -1, err)
+	return stat, err
+}
+
+func (ea *eventAccess) Search(query string, startDate, endDate time.Time, limit int) ([]models.SearchResult, error) {
+	start := time.Now()
+	results, err := ea.next.Search(query, startDate, endDate, limit)
+	ea.observe("Search", start, len(results), err)
+	return results, err
+}
+
+func (ea *eventAccess) Create(evt *models.Event) (string, error) {
+	start := time.Now()
+	uuid, err := ea.next.Create(evt)
+	ea.observe("Create", start, 1, err)
+	return uuid, err
+}
+
+func (ea *eventAccess) GetByUUID(uuid string) (*models.Event, error) {
+	start := time.Now()
+	evt, err := ea.next.GetByUUID(uuid)
+	ea.observe("GetByUUID", start, 1, err)
+	return evt, err
+}
+
+func (ea *eventAccess) Update(evt *models.Event) error {
+	start := time.Now()
+	err := ea.next.Update(evt)
+	ea.observe("Update", start, 1, err)
+	return err
+}
+
+func (ea *eventAccess) Patch(evt *models.Event, fields []models.EventField) error {
+	start := time.Now()
+	err := ea.next.Patch(evt, fields)
+	ea.observe("Patch", start, 1, err)
+	return err
+}
+
+func (ea *eventAccess) Delete(uuid string, version int) error {
+	start := time.Now()
+	err := ea.next.Delete(uuid, version)
+	ea.observe("Delete", start, 1, err)
+	return err
+}
+
+func (ea *eventAccess) GetTrash() ([]models.Event, error) {
+	start := time.Now()
+	evts, err := ea.next.GetTrash()
+	ea.observe("GetTrash", start, len(evts), err)
+	return evts, err
+}
+
+func (ea *eventAccess) Restore(uuid string) (*models.Event, error) {
+	start := time.Now()
+	evt, err := ea.next.Restore(uuid)
+	ea.observe("Restore", start, 1, err)
+	return evt, err
+}
+
+func (ea *eventAccess) Purge(before time.Time) (int64, error) {
+	start := time.Now()
+	n, err := ea.next.Purge(before)
+	ea.observe("Purge", start, int(n), err)
+	return n, err
+}
+
+// InTx times the whole transaction and wraps the transaction-bound access so
+// that the calls made in fn are recorded too.
+func (ea *eventAccess) InTx(fn func(tx models.EventAccess) error) error {
+	start := time.Now()
+	err := ea.next.InTx(func(tx models.EventAccess) error {
+		return fn(&eventAccess{next: tx, metrics: ea.metrics})
+	})
+	ea.observe("InTx", start, -1, err)
+	return err
+}
+
+func (ea *eventAccess) WithContext(ctx context.Context) models.EventAccess {
+	return &eventAccess{next: ea.next.WithContext(ctx), metrics: ea.metrics}
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Attachment describes a file attached to an event. The contents are kept in
+// a blob store under the UUID of the attachment.
+type Attachment struct {
+	ID          int       `json:"id"`
+	UUID        string    `json:"uuid"`
+	EventUUID   string    `json:"event_uuid"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// AttachmentAccess reads and writes the attachments of events. Like those of
+// comments, writes update the update time of the event, but not its version.
+type AttachmentAccess interface {
+	GetByEvent(eventUUID string) ([]Attachment, error)
+	Get(eventUUID, uuid string) (*Attachment, error)
+	// Create sets the ID and CreatedAt of a, and the UUID unless it is set
+	// already, e.g. because the blob has been stored under it.
+	Create(a *Attachment) error
+	Delete(eventUUID, uuid string) error
+	// Trashed returns the UUIDs of the attachments of events trashed before
+	// the given time, which a purge of the trash removes. Existing returns
+	// those of uuids that are still stored, e.g. because their event was
+	// restored.
+	Trashed(before time.Time) ([]string, error)
+	Existing(uuids []string) ([]string, error)
+}
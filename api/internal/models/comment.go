@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Comment is an entry in the discussion thread of an event.
+type Comment struct {
+	ID        int        `json:"id"`
+	EventUUID string     `json:"event_uuid"`
+	Author    string     `json:"author"`
+	Body      string     `json:"body"`
+	CreatedAt time.Time  `json:"created_at"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+}
+
+// CommentAccess reads and writes the comments of events. Writes count as
+// changes of the event and update its update time, but not its version.
+type CommentAccess interface {
+	// GetByEvent returns the comments of an event oldest first, skipping
+	// offset comments and returning at most limit.
+	GetByEvent(eventUUID string, limit, offset int) ([]Comment, error)
+	Get(eventUUID string, id int) (*Comment, error)
+	Create(c *Comment) error
+	// Update changes the body of c and sets EditedAt.
+	Update(c *Comment) error
+	Delete(eventUUID string, id int) error
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDate(t *testing.T) {
+	d := Date{2024, time.February, 28}
+	assert.Equal(t, Date{2024, time.March, 1}, d.AddDays(2))
+	assert.Equal(t, Date{2023, time.December, 31}, Date{2024, time.January, 1}.AddDays(-1))
+	assert.True(t, d.Before(d.AddDays(1)))
+	assert.False(t, d.Before(d))
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+	assert.Equal(t, Date{2023, time.October, 2}, DateOf(time.Date(2023, time.October, 1, 23, 30, 0, 0, time.UTC).In(berlin)))
+
+	b, err := json.Marshal(d)
+	assert.NoError(t, err)
+	assert.Equal(t, `"2024-02-28"`, string(b))
+
+	var parsed Date
+	assert.NoError(t, json.Unmarshal(b, &parsed))
+	assert.Equal(t, d, parsed)
+	assert.Error(t, json.Unmarshal([]byte(`"2024-02-30"`), &parsed))
+}
+
+func TestDateScan(t *testing.T) {
+	var d Date
+	assert.NoError(t, d.Scan(time.Date(2023, time.October, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, Date{2023, time.October, 1}, d)
+	assert.NoError(t, d.Scan([]byte("2023-10-02T00:00:00Z")))
+	assert.Equal(t, Date{2023, time.October, 2}, d)
+	assert.Error(t, d.Scan(42))
+}
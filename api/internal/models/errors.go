@@ -0,0 +1,51 @@
+package models
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+	ErrValidation = errors.New("validation failed")
+	ErrConstraint = errors.New("constraint violation")
+	// ErrPrecondition means that a conditional write found the stored
+	// version of a record to have moved on.
+	ErrPrecondition = errors.New("precondition failed")
+)
+
+type EventRef struct {
+	UUID  string `json:"uuid"`
+	Title string `json:"title"`
+}
+
+// StorageError is returned by the data access implementations. Kind is one of
+// the Err* sentinels above, so callers can use errors.Is to classify it. Code
+// is an optional machine-readable cause like "event_overlap".
+type StorageError struct {
+	Kind      error
+	Code      string
+	Detail    string
+	Conflicts []EventRef
+	Err       error
+}
+
+func (e *StorageError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Kind.Error())
+	if e.Detail != "" {
+		b.WriteString(": " + e.Detail)
+	}
+	if e.Err != nil {
+		b.WriteString(": " + e.Err.Error())
+	}
+	return b.String()
+}
+
+func (e *StorageError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
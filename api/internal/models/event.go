@@ -1,15 +1,99 @@
 package models
 
-import "time"
+import (
+	"api/internal/filter"
+	"context"
+	"sync"
+	"time"
+)
 
 type Event struct {
-	ID          int       `json:"id"`
-	UUID        string    `json:"uuid"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	DateFrom    time.Time `json:"date_from"`
-	DateTo      time.Time `json:"date_to"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID          int         `json:"id"`
+	UUID        string      `json:"uuid"`
+	Title       string      `json:"title"`
+	Description string      `json:"description"`
+	DateFrom    time.Time   `json:"date_from"`
+	DateTo      time.Time   `json:"date_to"`
+	TZFrom      string      `json:"tz_from,omitempty"`
+	TZTo        string      `json:"tz_to,omitempty"`
+	AllDay      bool        `json:"all_day"`
+	StartDate   *Date       `json:"start_date,omitempty"`
+	EndDate     *Date       `json:"end_date,omitempty"`
+	Location    *Location   `json:"location,omitempty"`
+	URL         string      `json:"url,omitempty"`
+	Conference  *Conference `json:"conference,omitempty"`
+	Tags        []Tag       `json:"tags"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+	Version     int         `json:"version"`
+	DeletedAt   *time.Time  `json:"deleted_at,omitempty"`
+}
+
+// Normalize converts DateFrom and DateTo to UTC and lets the event end in the
+// time zone it starts in unless told otherwise. For all-day events, which
+// last from StartDate through EndDate wherever they are looked at, it derives
+// them as floating times from midnight of StartDate to midnight after EndDate.
+func (evt *Event) Normalize() {
+	if evt.AllDay && evt.StartDate != nil && evt.EndDate != nil {
+		evt.DateFrom = evt.StartDate.In(time.UTC)
+		evt.DateTo = evt.EndDate.AddDays(1).In(time.UTC)
+		evt.TZFrom, evt.TZTo = "", ""
+		return
+	}
+	if evt.TZTo == "" {
+		evt.TZTo = evt.TZFrom
+	}
+	evt.DateFrom = evt.DateFrom.UTC()
+	evt.DateTo = evt.DateTo.UTC()
+}
+
+// Localize expresses DateFrom and DateTo in the time zones the event was
+// created in. Unknown zones leave the times as they are.
+func (evt *Event) Localize() {
+	if evt.TZFrom == "" || evt.TZTo == "" {
+		return
+	}
+	if loc, err := LoadLocation(evt.TZFrom); err == nil {
+		evt.DateFrom = evt.DateFrom.In(loc)
+	}
+	if loc, err := LoadLocation(evt.TZTo); err == nil {
+		evt.DateTo = evt.DateTo.In(loc)
+	}
+}
+
+var locations sync.Map
+
+// LoadLocation is time.LoadLocation with a cache, as reading the zone
+// database for every event is slow.
+func LoadLocation(name string) (*time.Location, error) {
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+type SearchResult struct {
+	Event
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+// ListStat summarizes the events in a window. It changes whenever the events
+// returned for the window would, so it can stand in for them in an ETag.
+type ListStat struct {
+	Count     int
+	UpdatedAt time.Time
+	// Events is a digest of the ID, version and update time of each event.
+	// Count and UpdatedAt miss a write that commits after a newer one, as
+	// its update time is older.
+	Events string
+	// Tags is a digest of all tags, as events embed their names and colors.
+	Tags string
 }
 
 type EventField int
@@ -24,6 +108,16 @@ const (
 	DateFrom
 	DateTo
 	CreatedAt
+	UpdatedAt
+	AllDay
+	StartDate
+	EndDate
+	TZFrom
+	TZTo
+	Tags
+	EventLocation
+	URL
+	EventConference
 )
 
 type SortOrder int
@@ -39,12 +133,39 @@ type EventAccess interface {
 	GetAll() ([]Event, error)
 	GetByFilter(
 		startDate, endDate time.Time,
+		where filter.Expr,
 		sortField EventField,
 		sortOrder SortOrder,
 		limit int,
 	) ([]Event, error)
+	// Stat summarizes the events GetByFilter would return for the window
+	// and filter, regardless of order and limit.
+	Stat(startDate, endDate time.Time, where filter.Expr) (ListStat, error)
+	Search(
+		query string,
+		startDate, endDate time.Time,
+		limit int,
+	) ([]SearchResult, error)
+	// Create and Update link the event to the tags in evt.Tags by their IDs
+	// and leave the links alone if evt.Tags is nil.
 	Create(evt *Event) (string, error)
 	GetByUUID(uuid string) (*Event, error)
 	Update(evt *Event) error
-	Delete(uuid string) error
+	// Patch updates only the given fields of evt and reloads evt from the
+	// stored row.
+	Patch(evt *Event, fields []EventField) error
+	// Delete moves an event to the trash. GetTrash lists the trashed events,
+	// Restore takes one out again and Purge finally removes those trashed
+	// before the given time.
+	Delete(uuid string, version int) error
+	GetTrash() ([]Event, error)
+	Restore(uuid string) (*Event, error)
+	Purge(before time.Time) (int64, error)
+	// InTx runs fn with an EventAccess bound to a transaction that is
+	// committed if fn returns nil and rolled back otherwise. Calling InTx
+	// on a transaction-bound EventAccess nests the transaction.
+	InTx(fn func(tx EventAccess) error) error
+	// WithContext returns an EventAccess whose statements run in ctx, so
+	// they are cancelled with a request and traced as part of it.
+	WithContext(ctx context.Context) EventAccess
 }
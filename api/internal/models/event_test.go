@@ -0,0 +1,33 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	start := Date{2023, time.December, 24}
+	end := Date{2023, time.December, 26}
+	evt := Event{AllDay: true, StartDate: &start, EndDate: &end}
+	evt.Normalize()
+	assert.Equal(t, time.Date(2023, time.December, 24, 0, 0, 0, 0, time.UTC), evt.DateFrom)
+	assert.Equal(t, time.Date(2023, time.December, 27, 0, 0, 0, 0, time.UTC), evt.DateTo)
+}
+
+func TestLocalize(t *testing.T) {
+	evt := Event{
+		DateFrom: time.Date(2023, time.October, 1, 7, 0, 0, 0, time.UTC),
+		DateTo:   time.Date(2023, time.October, 1, 14, 0, 0, 0, time.UTC),
+		TZFrom:   "Europe/Berlin",
+	}
+	evt.Normalize()
+	assert.Equal(t, "Europe/Berlin", evt.TZTo)
+	evt.Localize()
+	assert.Equal(t, 9, evt.DateFrom.Hour())
+	assert.Equal(t, "Europe/Berlin", evt.DateTo.Location().String())
+
+	_, err := LoadLocation("Nowhere/Special")
+	assert.Error(t, err)
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// Location is where an event takes place, optionally with coordinates in
+// decimal degrees.
+type Location struct {
+	Name      string   `json:"name"`
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+}
+
+// Conference holds the details for joining an event remotely.
+type Conference struct {
+	Provider string `json:"provider"`
+	JoinURL  string `json:"join_url"`
+	DialIn   string `json:"dial_in,omitempty"`
+}
+
+func (l Location) Value() (driver.Value, error) {
+	return json.Marshal(l)
+}
+
+func (l *Location) Scan(src any) error {
+	return scanJSON(src, l)
+}
+
+func (c Conference) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+func (c *Conference) Scan(src any) error {
+	return scanJSON(src, c)
+}
+
+func scanJSON(src any, v any) error {
+	switch src := src.(type) {
+	case []byte:
+		return json.Unmarshal(src, v)
+	case string:
+		return json.Unmarshal([]byte(src), v)
+	}
+	return fmt.Errorf("cannot scan %T into %T", src, v)
+}
@@ -0,0 +1,15 @@
+package models
+
+type Tag struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type TagAccess interface {
+	GetAll() ([]Tag, error)
+	GetByID(id int) (*Tag, error)
+	Create(tag *Tag) error
+	Update(tag *Tag) error
+	Delete(id int) error
+}
@@ -1,5 +1,7 @@
 package models
 
+import "context"
+
 type User struct {
 	ID       int    `json:"id"`
 	UUID     string `json:"uuid"`
@@ -7,3 +9,17 @@ type User struct {
 	Email    string `json:"email"`
 	Password []byte `json:"-"`
 }
+
+type userKey struct{}
+
+// WithUser returns a copy of ctx that carries the authenticated user of a
+// request, for the middleware that authenticates requests to set.
+func WithUser(ctx context.Context, user *User) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// UserFrom returns the authenticated user ctx carries, or nil.
+func UserFrom(ctx context.Context) *User {
+	user, _ := ctx.Value(userKey{}).(*User)
+	return user
+}
@@ -0,0 +1,265 @@
+// Package patch applies JSON Merge Patch (RFC 7396) and JSON Patch (RFC 6902)
+// documents to JSON values decoded into any.
+package patch
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Merge applies the merge patch to doc and returns the result. doc may be
+// modified in place.
+func Merge(doc, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	d, ok := doc.(map[string]any)
+	if !ok {
+		d = map[string]any{}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(d, k)
+			continue
+		}
+		d[k] = Merge(d[k], v)
+	}
+	return d
+}
+
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Apply applies the JSON Patch operations to doc in order and returns the
+// result. It stops at the first operation that fails.
+func Apply(doc any, ops []Operation) (any, error) {
+	for i, op := range ops {
+		var err error
+		doc, err = apply(doc, op)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	return doc, nil
+}
+
+func apply(doc any, op Operation) (any, error) {
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, fmt.Errorf("missing value")
+		}
+		var value any
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return nil, err
+		}
+		switch op.Op {
+		case "add":
+			return add(doc, op.Path, value)
+		case "replace":
+			doc, _, err := remove(doc, op.Path)
+			if err != nil {
+				return nil, err
+			}
+			return add(doc, op.Path, value)
+		}
+		current, err := get(doc, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(current, value) {
+			return nil, fmt.Errorf("test failed")
+		}
+		return doc, nil
+	case "remove":
+		doc, _, err := remove(doc, op.Path)
+		return doc, err
+	case "move":
+		if strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, fmt.Errorf("cannot move a value into one of its children")
+		}
+		doc, value, err := remove(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, op.Path, value)
+	case "copy":
+		value, err := get(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, op.Path, deepCopy(value))
+	}
+	return nil, fmt.Errorf("unknown op")
+}
+
+func parsePointer(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("invalid pointer %q", path)
+	}
+	tokens := strings.Split(path[1:], "/")
+	for i, tok := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func index(tok string, n int, appendable bool) (int, error) {
+	if appendable && tok == "-" {
+		return n, nil
+	}
+	i, err := strconv.Atoi(tok)
+	if err != nil || i < 0 || i > n || !appendable && i == n || tok != strconv.Itoa(i) {
+		return 0, fmt.Errorf("invalid array index %q", tok)
+	}
+	return i, nil
+}
+
+func get(doc any, path string) (any, error) {
+	tokens, err := parsePointer(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, tok := range tokens {
+		switch d := doc.(type) {
+		case map[string]any:
+			v, ok := d[tok]
+			if !ok {
+				return nil, fmt.Errorf("path %q does not exist", path)
+			}
+			doc = v
+		case []any:
+			i, err := index(tok, len(d), false)
+			if err != nil {
+				return nil, err
+			}
+			doc = d[i]
+		default:
+			return nil, fmt.Errorf("path %q does not exist", path)
+		}
+	}
+	return doc, nil
+}
+
+// update replaces the value at the parent of path with the result of fn,
+// which receives the parent and the last reference token.
+func update(doc any, path string, fn func(parent any, tok string) (any, error)) (any, error) {
+	tokens, err := parsePointer(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return fn(nil, "")
+	}
+	var rec func(node any, tokens []string) (any, error)
+	rec = func(node any, tokens []string) (any, error) {
+		if len(tokens) == 1 {
+			return fn(node, tokens[0])
+		}
+		switch n := node.(type) {
+		case map[string]any:
+			child, ok := n[tokens[0]]
+			if !ok {
+				return nil, fmt.Errorf("path %q does not exist", path)
+			}
+			child, err := rec(child, tokens[1:])
+			if err != nil {
+				return nil, err
+			}
+			n[tokens[0]] = child
+			return n, nil
+		case []any:
+			i, err := index(tokens[0], len(n), false)
+			if err != nil {
+				return nil, err
+			}
+			child, err := rec(n[i], tokens[1:])
+			if err != nil {
+				return nil, err
+			}
+			n[i] = child
+			return n, nil
+		}
+		return nil, fmt.Errorf("path %q does not exist", path)
+	}
+	return rec(doc, tokens)
+}
+
+func add(doc any, path string, value any) (any, error) {
+	return update(doc, path, func(parent any, tok string) (any, error) {
+		switch p := parent.(type) {
+		case nil:
+			return value, nil
+		case map[string]any:
+			p[tok] = value
+			return p, nil
+		case []any:
+			i, err := index(tok, len(p), true)
+			if err != nil {
+				return nil, err
+			}
+			p = append(p, nil)
+			copy(p[i+1:], p[i:])
+			p[i] = value
+			return p, nil
+		}
+		return nil, fmt.Errorf("path %q does not exist", path)
+	})
+}
+
+func remove(doc any, path string) (any, any, error) {
+	var removed any
+	doc, err := update(doc, path, func(parent any, tok string) (any, error) {
+		switch p := parent.(type) {
+		case nil:
+			removed = doc
+			return nil, nil
+		case map[string]any:
+			v, ok := p[tok]
+			if !ok {
+				return nil, fmt.Errorf("path %q does not exist", path)
+			}
+			removed = v
+			delete(p, tok)
+			return p, nil
+		case []any:
+			i, err := index(tok, len(p), false)
+			if err != nil {
+				return nil, err
+			}
+			removed = p[i]
+			return append(p[:i], p[i+1:]...), nil
+		}
+		return nil, fmt.Errorf("path %q does not exist", path)
+	})
+	return doc, removed, err
+}
+
+func deepCopy(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[k] = deepCopy(e)
+		}
+		return m
+	case []any:
+		s := make([]any, len(v))
+		for i, e := range v {
+			s[i] = deepCopy(e)
+		}
+		return s
+	}
+	return v
+}
@@ -0,0 +1,98 @@
+// Package ratelimit throttles clients with token buckets.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit allows Rate requests per second on average and bursts of up to Burst
+// requests.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+type Result struct {
+	Allowed   bool
+	Remaining int
+	// RetryAfter is how long to wait until the next token is available, if
+	// the request was not allowed.
+	RetryAfter time.Duration
+}
+
+// Store keeps the buckets of all clients. MemoryStore keeps them in the
+// process; servers sharing one limit need a store backed by a shared
+// database.
+type Store interface {
+	// Take removes a token from the bucket of key at time now, refilling it
+	// according to limit first.
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take refills b up to now and removes a token if there is one.
+func (b *bucket) take(limit Limit, now time.Time) Result {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(limit.Burst), b.tokens+elapsed*limit.Rate)
+		b.last = now
+	}
+	if b.tokens >= 1 {
+		b.tokens--
+		return Result{Allowed: true, Remaining: int(b.tokens)}
+	}
+	wait := (1 - b.tokens) / limit.Rate
+	return Result{RetryAfter: time.Duration(wait * float64(time.Second))}
+}
+
+const sweepInterval = time.Minute
+
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: map[string]*bucket{},
+	}
+}
+
+func (ms *MemoryStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if now.Sub(ms.lastSweep) > sweepInterval {
+		ms.sweep(limit, now)
+	}
+	b, ok := ms.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		ms.buckets[key] = b
+	}
+	return b.take(limit, now), nil
+}
+
+// sweep forgets the buckets that have refilled completely, as a new bucket
+// is just the same.
+func (ms *MemoryStore) sweep(limit Limit, now time.Time) {
+	full := time.Duration(float64(limit.Burst) / limit.Rate * float64(time.Second))
+	for key, b := range ms.buckets {
+		if now.Sub(b.last) >= full {
+			delete(ms.buckets, key)
+		}
+	}
+	ms.lastSweep = now
+}
+
+func (ms *MemoryStore) Len() int {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return len(ms.buckets)
+}
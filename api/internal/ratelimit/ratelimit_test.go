@@ -0,0 +1,50 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	limit := Limit{Rate: 2, Burst: 3}
+	now := time.Date(2023, time.October, 1, 12, 0, 0, 0, time.UTC)
+	ms := NewMemoryStore()
+
+	t.Run("Burst", func(t *testing.T) {
+		for i := 2; i >= 0; i-- {
+			res, err := ms.Take(ctx, "a", limit, now)
+			assert.NoError(t, err)
+			assert.True(t, res.Allowed)
+			assert.Equal(t, i, res.Remaining)
+		}
+		res, _ := ms.Take(ctx, "a", limit, now)
+		assert.False(t, res.Allowed)
+		assert.Equal(t, 500*time.Millisecond, res.RetryAfter)
+	})
+
+	t.Run("Other Key", func(t *testing.T) {
+		res, _ := ms.Take(ctx, "b", limit, now)
+		assert.True(t, res.Allowed)
+	})
+
+	t.Run("Refill", func(t *testing.T) {
+		res, _ := ms.Take(ctx, "a", limit, now.Add(250*time.Millisecond))
+		assert.False(t, res.Allowed)
+		assert.Equal(t, 250*time.Millisecond, res.RetryAfter)
+		res, _ = ms.Take(ctx, "a", limit, now.Add(500*time.Millisecond))
+		assert.True(t, res.Allowed)
+		res, _ = ms.Take(ctx, "a", limit, now.Add(10*time.Second))
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 2, res.Remaining)
+	})
+
+	t.Run("Sweep", func(t *testing.T) {
+		assert.Equal(t, 2, ms.Len())
+		ms.Take(ctx, "c", limit, now.Add(time.Hour))
+		assert.Equal(t, 1, ms.Len())
+	})
+}
@@ -0,0 +1,113 @@
+package router
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		accept []string
+		want   string
+	}{
+		{nil, ""},
+		{[]string{"gzip, deflate, br"}, "br"},
+		{[]string{"gzip", "br;q=0"}, "gzip"},
+		{[]string{"deflate"}, ""},
+		{[]string{"*"}, "br"},
+		{[]string{"br;q=0, *;q=0.5"}, "gzip"},
+		{[]string{"GZIP;q=0.8"}, "gzip"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, negotiateEncoding(tt.accept), tt.accept)
+	}
+}
+
+func TestCompress(t *testing.T) {
+	body := strings.Repeat(`{"title":"Event One"},`, 100)
+	handler := func(contentType, body string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", contentType)
+			w.Header().Set("ETag", `"1"`)
+			io.WriteString(w, body)
+		})
+	}
+	config := viper.New()
+	request := func(h http.Handler, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/events", nil)
+		if accept != "" {
+			req.Header.Set("Accept-Encoding", accept)
+		}
+		w := httptest.NewRecorder()
+		compress(config)(h).ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("gzip", func(t *testing.T) {
+		w := request(handler("application/json", body), "gzip")
+		assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+		assert.Equal(t, `W/"1"`, w.Header().Get("ETag"))
+		zr, err := gzip.NewReader(w.Body)
+		if assert.NoError(t, err) {
+			got, err := io.ReadAll(zr)
+			assert.NoError(t, err)
+			assert.Equal(t, body, string(got))
+		}
+	})
+
+	t.Run("br", func(t *testing.T) {
+		w := request(handler("application/json", body), "gzip, br")
+		assert.Equal(t, "br", w.Header().Get("Content-Encoding"))
+		got, err := io.ReadAll(brotli.NewReader(w.Body))
+		assert.NoError(t, err)
+		assert.Equal(t, body, string(got))
+	})
+
+	t.Run("not accepted", func(t *testing.T) {
+		w := request(handler("application/json", body), "")
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Equal(t, `"1"`, w.Header().Get("ETag"))
+		assert.Equal(t, body, w.Body.String())
+	})
+
+	t.Run("small", func(t *testing.T) {
+		w := request(handler("application/json", `{"message":"success"}`), "gzip")
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Equal(t, `{"message":"success"}`, w.Body.String())
+	})
+
+	t.Run("incompressible", func(t *testing.T) {
+		w := request(handler("image/png", body), "gzip")
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Equal(t, body, w.Body.String())
+	})
+
+	t.Run("not modified", func(t *testing.T) {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("ETag", `"1"`)
+			w.WriteHeader(http.StatusNotModified)
+		})
+		w := request(h, "gzip")
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Empty(t, w.Body.String())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		config.Set("compression.enabled", false)
+		defer config.Set("compression.enabled", true)
+		w := request(handler("application/json", body), "gzip")
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Equal(t, []string{"Accept-Encoding"}, w.Header().Values("Vary"))
+		assert.Equal(t, body, w.Body.String())
+	})
+}
@@ -0,0 +1,95 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"runtime/debug"
+	"time"
+)
+
+const checkTimeout = 2 * time.Second
+
+// Check reports whether a dependency of the server, like the database or a
+// background worker, is ready.
+type Check struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// unloggedPaths are polled by load balancers, probes and Prometheus and would
+// drown the request log.
+var unloggedPaths = map[string]bool{
+	"/metrics": true,
+	"/healthz": true,
+	"/readyz":  true,
+	"/version": true,
+}
+
+func healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"status": "ok"})
+}
+
+// readyz runs all checks concurrently and answers 503 if any of them fails.
+func readyz(checks []Check) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), checkTimeout)
+		defer cancel()
+
+		errs := make([]error, len(checks))
+		done := make(chan int)
+		for i := range checks {
+			go func(i int) {
+				errs[i] = checks[i].Check(ctx)
+				done <- i
+			}(i)
+		}
+		for range checks {
+			<-done
+		}
+
+		statusCode, status := http.StatusOK, "ok"
+		results := map[string]string{}
+		for i, check := range checks {
+			results[check.Name] = "ok"
+			if errs[i] != nil {
+				results[check.Name] = errs[i].Error()
+				statusCode, status = http.StatusServiceUnavailable, "unavailable"
+			}
+		}
+		writeJSON(w, statusCode, map[string]any{"status": status, "checks": results})
+	}
+}
+
+// version reports the module version and the VCS revision the binary was
+// built from.
+func version(w http.ResponseWriter, r *http.Request) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		writeJSON(w, http.StatusOK, map[string]any{})
+		return
+	}
+	m := map[string]any{
+		"go_version": info.GoVersion,
+		"path":       info.Main.Path,
+		"version":    info.Main.Version,
+	}
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			m["revision"] = s.Value
+		case "vcs.time":
+			m["time"] = s.Value
+		case "vcs.modified":
+			m["modified"] = s.Value == "true"
+		}
+	}
+	writeJSON(w, http.StatusOK, m)
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, data any) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(statusCode)
+	return json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,49 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadyz(t *testing.T) {
+	ok := Check{Name: "database", Check: func(ctx context.Context) error { return nil }}
+	failing := Check{Name: "purger", Check: func(ctx context.Context) error { return errors.New("purger has not run yet") }}
+
+	tests := []struct {
+		name       string
+		checks     []Check
+		statusCode int
+		want       map[string]string
+	}{
+		{"No Checks", nil, http.StatusOK, map[string]string{}},
+		{"All Ok", []Check{ok}, http.StatusOK, map[string]string{"database": "ok"}},
+		{"One Failing", []Check{ok, failing}, http.StatusServiceUnavailable, map[string]string{"database": "ok", "purger": "purger has not run yet"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			readyz(tt.checks)(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			assert.Equal(t, tt.statusCode, w.Code)
+			var body struct {
+				Checks map[string]string `json:"checks"`
+			}
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			assert.Equal(t, tt.want, body.Checks)
+		})
+	}
+}
+
+func TestVersion(t *testing.T) {
+	w := httptest.NewRecorder()
+	version(w, httptest.NewRequest(http.MethodGet, "/version", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body map[string]any
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Contains(t, body, "go_version")
+}
@@ -0,0 +1,115 @@
+package router
+
+import (
+	"api/internal/controller"
+	"api/internal/logging"
+	"api/internal/models"
+	"api/internal/ratelimit"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/spf13/viper"
+)
+
+const (
+	defaultMaxBodySize = 8 << 20
+	defaultRate        = 10
+	defaultBurst       = 40
+	// uploadRoute names the routes that limit their bodies themselves.
+	uploadRoute = "upload"
+)
+
+// rateLimit throttles the API requests of each client to limits.rate requests
+// per second with bursts of limits.burst. Other paths, like the client assets
+// a page load fetches many of, are not limited. A rate of zero turns the limit
+// off, while a burst below one, which would refuse every request, falls back to
+// the default. Clients are told apart by clientKey, so authentication has to
+// run before it.
+func rateLimit(store ratelimit.Store, config *viper.Viper) func(http.Handler) http.Handler {
+	limit := ratelimit.Limit{Rate: defaultRate, Burst: defaultBurst}
+	if config.IsSet("limits.rate") {
+		limit.Rate = config.GetFloat64("limits.rate")
+	}
+	if config.IsSet("limits.burst") && config.GetInt("limits.burst") > 0 {
+		limit.Burst = config.GetInt("limits.burst")
+	}
+	trustProxy := config.GetBool("limits.trust_proxy")
+
+	return func(h http.Handler) http.Handler {
+		if limit.Rate <= 0 {
+			return h
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !strings.HasPrefix(r.URL.Path, "/api/") || r.Method == http.MethodOptions {
+				h.ServeHTTP(w, r)
+				return
+			}
+			res, err := store.Take(r.Context(), clientKey(r, trustProxy), limit, time.Now())
+			if err != nil {
+				// Rather serve too much than nothing when the store is down.
+				logging.FromContext(r.Context()).Error("rate limit", "error", err)
+				h.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(limit.Burst))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			if !res.Allowed {
+				retryAfter := int(math.Ceil(res.RetryAfter.Seconds()))
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+				controller.WriteProblem(w, r, http.StatusTooManyRequests, "rate_limited", "too many requests, retry in "+strconv.Itoa(retryAfter)+"s")
+				return
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientKey names the bucket of the client of r: the authenticated user if
+// there is one, so that users behind the same address do not share a bucket,
+// and the address otherwise.
+func clientKey(r *http.Request, trustProxy bool) string {
+	if user := models.UserFrom(r.Context()); user != nil && user.UUID != "" {
+		return "user:" + user.UUID
+	}
+	return "ip:" + clientIP(r, trustProxy)
+}
+
+// clientIP returns the address of the client of r. With limits.trust_proxy the
+// last address in X-Forwarded-For is used, as added by our own proxy.
+func clientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if fwd := r.Header.Values("X-Forwarded-For"); len(fwd) > 0 {
+			hops := strings.Split(fwd[len(fwd)-1], ",")
+			if ip := strings.TrimSpace(hops[len(hops)-1]); ip != "" {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// bodyLimit caps request bodies at limits.max_body_size, except for routes
+// named uploadRoute. It must be installed with mux.Router.Use.
+func bodyLimit(config *viper.Viper) mux.MiddlewareFunc {
+	maxSize := int64(defaultMaxBodySize)
+	if config.IsSet("limits.max_body_size") {
+		maxSize = config.GetInt64("limits.max_body_size")
+	}
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if cr := mux.CurrentRoute(r); cr == nil || cr.GetName() != uploadRoute {
+				r.Body = http.MaxBytesReader(w, r.Body, maxSize)
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+}
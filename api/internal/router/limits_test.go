@@ -0,0 +1,85 @@
+package router
+
+import (
+	"api/internal/models"
+	"api/internal/ratelimit"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	config := viper.New()
+	config.Set("limits.rate", 1)
+	config.Set("limits.burst", 2)
+	h := rateLimit(ratelimit.NewMemoryStore(), config)(http.NotFoundHandler())
+
+	request := func(path, addr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = addr
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusNotFound, request("/api/events", "10.0.0.1:1000").Code)
+	assert.Equal(t, http.StatusNotFound, request("/api/events", "10.0.0.1:1001").Code)
+	w := request("/api/events", "10.0.0.1:1002")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+
+	assert.Equal(t, http.StatusNotFound, request("/api/events", "10.0.0.2:1000").Code)
+	assert.Equal(t, http.StatusNotFound, request("/healthz", "10.0.0.1:1003").Code)
+	assert.Equal(t, http.StatusNotFound, request("/bundle.3f2a9c81d0e4b7a5.js", "10.0.0.1:1004").Code)
+
+	config.Set("limits.burst", 0)
+	h = rateLimit(ratelimit.NewMemoryStore(), config)(http.NotFoundHandler())
+	w = request("/api/events", "10.0.0.1:1000")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, strconv.Itoa(defaultBurst), w.Header().Get("RateLimit-Limit"))
+}
+
+func TestClientIP(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Add("X-Forwarded-For", "1.1.1.1, 2.2.2.2")
+	assert.Equal(t, "10.0.0.1", clientIP(req, false))
+	assert.Equal(t, "2.2.2.2", clientIP(req, true))
+}
+
+func TestClientKey(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/events", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	assert.Equal(t, "ip:10.0.0.1", clientKey(req, false))
+
+	req = req.WithContext(models.WithUser(req.Context(), &models.User{UUID: "u1"}))
+	assert.Equal(t, "user:u1", clientKey(req, false))
+}
+
+func TestBodyLimit(t *testing.T) {
+	config := viper.New()
+	config.Set("limits.max_body_size", 4)
+	read := func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		}
+	}
+	r := mux.NewRouter()
+	r.HandleFunc("/small", read)
+	r.HandleFunc("/upload", read).Name(uploadRoute)
+	r.Use(bodyLimit(config))
+
+	for path, want := range map[string]int{"/small": http.StatusRequestEntityTooLarge, "/upload": http.StatusOK} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, strings.NewReader("too long")))
+		assert.Equal(t, want, w.Code, path)
+	}
+}
@@ -1,9 +1,14 @@
 package router
 
 import (
+	cfg "api/internal/config"
 	"api/internal/controller"
+	"api/internal/logging"
+	"api/internal/metrics"
+	"api/internal/ratelimit"
+	"api/internal/tracing"
+	"api/internal/web"
 	"net/http"
-	"os"
 
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
@@ -19,17 +24,35 @@ func Use(r http.Handler, middleware ...MiddlewareFunc) http.Handler {
 	return r
 }
 
-func New(controller *controller.Controller, config *viper.Viper) http.Handler {
+// New creates the handler of the API. Requests are recorded in metrics unless
+// it is nil and throttled with the buckets in limits, checks are run by the
+// readiness probe.
+func New(controller *controller.Controller, config *viper.Viper, metrics *metrics.Metrics, limits ratelimit.Store, checks ...Check) (http.Handler, error) {
 	r := mux.NewRouter()
 
-	corsMiddleware := handlers.CORS(
-		handlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type", "Authorization"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "HEAD", "OPTIONS"}),
-	)
+	cors, err := cfg.CORSFrom(config)
+	if err != nil {
+		return nil, err
+	}
+	corsOptions := []handlers.CORSOption{
+		handlers.AllowedOrigins(cors.AllowedOrigins),
+		handlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type", "Authorization", "If-Match", "If-None-Match", "Traceparent", "Tracestate", logging.RequestIDHeader}),
+		handlers.AllowedMethods(cors.AllowedMethods),
+		handlers.ExposedHeaders([]string{"ETag", "Retry-After", logging.RequestIDHeader}),
+	}
+	if cors.AllowCredentials {
+		corsOptions = append(corsOptions, handlers.AllowCredentials())
+	}
+	corsMiddleware := handlers.CORS(corsOptions...)
 
-	logMiddleware := func(h http.Handler) http.Handler {
-		return handlers.LoggingHandler(os.Stdout, h)
+	r.HandleFunc("/healthz", healthz).Methods(http.MethodGet, http.MethodHead)
+	r.HandleFunc("/readyz", readyz(checks)).Methods(http.MethodGet, http.MethodHead)
+	r.HandleFunc("/version", version).Methods(http.MethodGet)
+	if metrics != nil {
+		r.Handle("/metrics", metrics.Handler()).Methods(http.MethodGet)
 	}
+	r.Use(tracing.Route)
+	r.Use(bodyLimit(config))
 
 	r.HandleFunc("/api/events", controller.GetEvents).Methods(http.MethodGet)
 	r.HandleFunc("/api/events/day", controller.GetEventsByDay).
@@ -41,11 +64,39 @@ func New(controller *controller.Controller, config *viper.Viper) http.Handler {
 	r.HandleFunc("/api/events/month", controller.GetEventsByMonth).
 		Methods(http.MethodGet).
 		Queries("year", "{year:.*}", "month", "{month:.*}", "tz", "{tz:.*}")
+	r.HandleFunc("/api/events/search", controller.SearchEvents).Methods(http.MethodGet)
+	r.HandleFunc("/api/events/export.ics", controller.ExportEvents).Methods(http.MethodGet)
 	r.HandleFunc("/api/events", controller.CreateEvent).Methods(http.MethodPost)
+	r.HandleFunc("/api/events/batch", controller.BatchEvents).Methods(http.MethodPost)
 	r.HandleFunc("/api/events/{uuid}", controller.GetEvent).Methods(http.MethodGet)
 	r.HandleFunc("/api/events/{uuid}", controller.UpdateEvent).Methods(http.MethodPut)
+	r.HandleFunc("/api/events/{uuid}", controller.PatchEvent).Methods(http.MethodPatch)
 	r.HandleFunc("/api/events/{uuid}", controller.DeleteEvent).Methods(http.MethodDelete)
-	r.PathPrefix("/").Handler(http.FileServer(http.Dir(config.GetString("frontend.path"))))
+	r.HandleFunc("/api/events/{uuid}/restore", controller.RestoreEvent).Methods(http.MethodPost)
+	r.HandleFunc("/api/events/{uuid}/attachments", controller.GetAttachments).Methods(http.MethodGet)
+	r.HandleFunc("/api/events/{uuid}/attachments", controller.CreateAttachment).Methods(http.MethodPost).Name(uploadRoute)
+	r.HandleFunc("/api/events/{uuid}/attachments/{id}", controller.GetAttachment).Methods(http.MethodGet)
+	r.HandleFunc("/api/events/{uuid}/attachments/{id}", controller.DeleteAttachment).Methods(http.MethodDelete)
+	r.HandleFunc("/api/events/{uuid}/comments", controller.GetComments).Methods(http.MethodGet)
+	r.HandleFunc("/api/events/{uuid}/comments", controller.CreateComment).Methods(http.MethodPost)
+	r.HandleFunc("/api/events/{uuid}/comments/{id:[0-9]+}", controller.UpdateComment).Methods(http.MethodPut)
+	r.HandleFunc("/api/events/{uuid}/comments/{id:[0-9]+}", controller.DeleteComment).Methods(http.MethodDelete)
+	r.HandleFunc("/api/calendar", controller.GetCalendar).Methods(http.MethodGet)
+	r.HandleFunc("/api/trash", controller.GetTrash).Methods(http.MethodGet)
+	r.HandleFunc("/api/tags", controller.GetTags).Methods(http.MethodGet)
+	r.HandleFunc("/api/tags", controller.CreateTag).Methods(http.MethodPost)
+	r.HandleFunc("/api/tags/{id:[0-9]+}", controller.GetTag).Methods(http.MethodGet)
+	r.HandleFunc("/api/tags/{id:[0-9]+}", controller.UpdateTag).Methods(http.MethodPut)
+	r.HandleFunc("/api/tags/{id:[0-9]+}", controller.DeleteTag).Methods(http.MethodDelete)
+	static, err := web.FS(config)
+	if err != nil {
+		return nil, err
+	}
+	r.PathPrefix("/").Handler(web.Handler(static))
 
-	return Use(r, corsMiddleware, logMiddleware)
+	var h http.Handler = r
+	if metrics != nil {
+		h = metrics.Router(r)
+	}
+	return Use(h, securityHeaders(config), rateLimit(limits, config), corsMiddleware, compress(config), logging.AccessLog(unloggedPaths), tracing.Middleware, logging.RequestID), nil
 }
@@ -0,0 +1,132 @@
+package router
+
+import (
+	"api/internal/controller"
+	"api/internal/models"
+	"api/internal/ratelimit"
+	"api/internal/storage"
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// fakeEvents keeps events in memory. Methods the tests do not need panic via
+// the nil embedded interface.
+type fakeEvents struct {
+	models.EventAccess
+	events map[string]models.Event
+}
+
+func (f *fakeEvents) WithContext(ctx context.Context) models.EventAccess {
+	return f
+}
+
+func (f *fakeEvents) GetByUUID(uuid string) (*models.Event, error) {
+	evt, ok := f.events[uuid]
+	if !ok {
+		return nil, &models.StorageError{Kind: models.ErrNotFound, Detail: "event does not exist"}
+	}
+	return &evt, nil
+}
+
+func (f *fakeEvents) Update(evt *models.Event) error {
+	stored, err := f.GetByUUID(evt.UUID)
+	if err != nil {
+		return err
+	}
+	if evt.Version != 0 && evt.Version != stored.Version {
+		return &models.StorageError{Kind: models.ErrPrecondition, Detail: "event has changed"}
+	}
+	evt.Version = stored.Version + 1
+	f.events[evt.UUID] = *evt
+	return nil
+}
+
+func newTestRouter(t *testing.T, config *viper.Viper, s *storage.Storage) http.Handler {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.html"), []byte("<html>index</html>"), 0o644))
+	config.Set("frontend.path", dir)
+	c, err := controller.New(s, nil, config)
+	require.NoError(t, err)
+	h, err := New(c, config, nil, ratelimit.NewMemoryStore())
+	require.NoError(t, err)
+	return h
+}
+
+func TestAccessLogTraceID(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { provider.Shutdown(context.Background()) })
+
+	var buf bytes.Buffer
+	logger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(logger) })
+
+	h := newTestRouter(t, viper.New(), &storage.Storage{})
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/calendar", nil))
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "request", line["msg"])
+	if spans := recorder.Ended(); assert.Len(t, spans, 1) {
+		assert.Equal(t, "GET /api/calendar", spans[0].Name())
+		assert.Equal(t, spans[0].SpanContext().TraceID().String(), line["trace_id"])
+	}
+}
+
+func TestVary(t *testing.T) {
+	h := newTestRouter(t, viper.New(), &storage.Storage{})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"Accept-Encoding"}, w.Header().Values("Vary"))
+}
+
+func TestCompressedETag(t *testing.T) {
+	from := time.Date(2023, time.October, 1, 10, 0, 0, 0, time.UTC)
+	events := &fakeEvents{events: map[string]models.Event{
+		"a": {UUID: "a", Title: "Planning", Description: strings.Repeat("Agenda. ", 200), DateFrom: from, DateTo: from.Add(time.Hour), Version: 1},
+	}}
+	h := newTestRouter(t, viper.New(), &storage.Storage{Event: events})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/events/a", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	etag := w.Header().Get("ETag")
+	assert.Equal(t, `W/"1"`, etag)
+
+	body := `{"title": "Planning", "date_from": "2023-10-01T10:00:00Z", "date_to": "2023-10-01T11:00:00Z"}`
+	put := func(ifMatch string) int {
+		req := httptest.NewRequest(http.MethodPut, "/api/events/a", strings.NewReader(body))
+		req.Header.Set("Accept-Encoding", "gzip")
+		req.Header.Set("If-Match", ifMatch)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusOK, put(etag))
+	assert.Equal(t, 2, events.events["a"].Version)
+	assert.Equal(t, http.StatusPreconditionFailed, put(etag))
+}
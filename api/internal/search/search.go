@@ -0,0 +1,170 @@
+// Package search provides full-text matching for backends without native
+// support for it.
+package search
+
+import (
+	"api/internal/models"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	StartSel = "<mark>"
+	StopSel  = "</mark>"
+	MaxWords = 24
+
+	titleWeight       = 1.0
+	descriptionWeight = 0.4
+	locationWeight    = 0.2
+)
+
+// Terms splits q into lower-cased words of letters and digits only.
+func Terms(q string) []string {
+	return strings.FieldsFunc(strings.ToLower(q), isSeparator)
+}
+
+// Naive returns the events in which every term of q prefix-matches a word of
+// the title, description, location name or conference provider, ordered by
+// rank.
+func Naive(evts []models.Event, q string, limit int) []models.SearchResult {
+	terms := Terms(q)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var results []models.SearchResult
+	for _, evt := range evts {
+		titleWords := Terms(evt.Title)
+		descriptionWords := Terms(evt.Description)
+		var locationWords []string
+		if evt.Location != nil {
+			locationWords = Terms(evt.Location.Name)
+		}
+		if evt.Conference != nil {
+			locationWords = append(locationWords, Terms(evt.Conference.Provider)...)
+		}
+
+		var rank float64
+		matchesAll := true
+		for _, term := range terms {
+			title := countPrefixed(titleWords, term)
+			description := countPrefixed(descriptionWords, term)
+			location := countPrefixed(locationWords, term)
+			if title+description+location == 0 {
+				matchesAll = false
+				break
+			}
+			rank += titleWeight*float64(title) + descriptionWeight*float64(description) + locationWeight*float64(location)
+		}
+		if !matchesAll {
+			continue
+		}
+
+		results = append(results, models.SearchResult{
+			Event:   evt,
+			Rank:    rank / math.Log2(float64(len(titleWords)+len(descriptionWords)+len(locationWords))+2),
+			Snippet: Highlight(evt.Title+" "+evt.Description, terms),
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].DateFrom.Before(results[j].DateFrom)
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// Highlight marks the words of text matching terms and cuts it down to
+// MaxWords around the first match.
+func Highlight(text string, terms []string) string {
+	type word struct {
+		start, end int
+		match      bool
+	}
+
+	var (
+		words []word
+		first = -1
+	)
+	start := -1
+	for i, r := range text + " " {
+		if !isSeparator(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start < 0 {
+			continue
+		}
+		w := word{start: start, end: i}
+		w.match = hasAnyPrefix(strings.ToLower(text[w.start:w.end]), terms)
+		if w.match && first < 0 {
+			first = len(words)
+		}
+		words = append(words, w)
+		start = -1
+	}
+	if len(words) == 0 {
+		return ""
+	}
+
+	from, to := 0, len(words)
+	if len(words) > MaxWords {
+		from = first - MaxWords/4
+		if from < 0 {
+			from = 0
+		}
+		to = from + MaxWords
+		if to > len(words) {
+			to = len(words)
+			from = to - MaxWords
+		}
+	}
+
+	var b strings.Builder
+	prev := words[from].start
+	for _, w := range words[from:to] {
+		b.WriteString(text[prev:w.start])
+		if w.match {
+			b.WriteString(StartSel)
+			b.WriteString(text[w.start:w.end])
+			b.WriteString(StopSel)
+		} else {
+			b.WriteString(text[w.start:w.end])
+		}
+		prev = w.end
+	}
+	return b.String()
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+func countPrefixed(words []string, term string) int {
+	var n int
+	for _, w := range words {
+		if strings.HasPrefix(w, term) {
+			n++
+		}
+	}
+	return n
+}
+
+func hasAnyPrefix(word string, terms []string) bool {
+	for _, term := range terms {
+		if strings.HasPrefix(word, term) {
+			return true
+		}
+	}
+	return false
+}
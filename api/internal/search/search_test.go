@@ -0,0 +1,99 @@
+package search
+
+import (
+	"api/internal/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var events = []models.Event{
+	{
+		UUID:        "1",
+		Title:       "Weekly standup",
+		Description: "Status of the database migration",
+		DateFrom:    time.Date(2023, time.October, 2, 9, 0, 0, 0, time.UTC),
+	},
+	{
+		UUID:        "2",
+		Title:       "Migration planning",
+		Description: "Decide how to migrate the events table",
+		DateFrom:    time.Date(2023, time.October, 3, 9, 0, 0, 0, time.UTC),
+	},
+	{
+		UUID:        "3",
+		Title:       "Lunch",
+		Description: "Try out the new restaurant",
+		DateFrom:    time.Date(2023, time.October, 1, 12, 0, 0, 0, time.UTC),
+		Location:    &models.Location{Name: "Trattoria Roma"},
+		Conference:  &models.Conference{Provider: "Jitsi", JoinURL: "https://meet.jit.si/lunch"},
+	},
+}
+
+func uuids(results []models.SearchResult) []string {
+	var ids []string
+	for _, res := range results {
+		ids = append(ids, res.UUID)
+	}
+	return ids
+}
+
+func TestTerms(t *testing.T) {
+	assert.Equal(t, []string{"that", "meeting", "about", "the", "migration"}, Terms("that meeting, about 'the' MIGRATION!"))
+	assert.Empty(t, Terms(" &|!:* "))
+}
+
+func TestNaive(t *testing.T) {
+	t.Run("Title Ranks Higher", func(t *testing.T) {
+		results := Naive(events, "migration", 0)
+		assert.Equal(t, []string{"2", "1"}, uuids(results))
+		assert.Greater(t, results[0].Rank, results[1].Rank)
+	})
+
+	t.Run("Prefix Match", func(t *testing.T) {
+		results := Naive(events, "migr", 0)
+		assert.Equal(t, []string{"2", "1"}, uuids(results))
+	})
+
+	t.Run("All Terms Required", func(t *testing.T) {
+		results := Naive(events, "migration standup", 0)
+		assert.Equal(t, []string{"1"}, uuids(results))
+	})
+
+	t.Run("Location And Conference", func(t *testing.T) {
+		assert.Equal(t, []string{"3"}, uuids(Naive(events, "trattoria", 0)))
+		assert.Equal(t, []string{"3"}, uuids(Naive(events, "jitsi lunch", 0)))
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		results := Naive(events, "the", 1)
+		assert.Len(t, results, 1)
+	})
+
+	t.Run("No Match", func(t *testing.T) {
+		assert.Empty(t, Naive(events, "retro", 0))
+		assert.Empty(t, Naive(events, "   ", 0))
+	})
+}
+
+func TestHighlight(t *testing.T) {
+	t.Run("Marks Matches", func(t *testing.T) {
+		assert.Equal(t,
+			"Weekly <mark>standup</mark>: status of the <mark>migration</mark>",
+			Highlight("Weekly standup: status of the migration", []string{"stand", "migration"}),
+		)
+	})
+
+	t.Run("Cuts Long Texts", func(t *testing.T) {
+		var words []string
+		for i := 0; i < 3*MaxWords; i++ {
+			words = append(words, "word")
+		}
+		words[2*MaxWords] = "needle"
+		snippet := Highlight(strings.Join(words, " "), []string{"needle"})
+		assert.Contains(t, snippet, "<mark>needle</mark>")
+		assert.Len(t, strings.Fields(snippet), MaxWords)
+	})
+}
@@ -0,0 +1,155 @@
+// Package server runs the HTTP server from startup to graceful shutdown.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+const (
+	defaultReadHeaderTimeout = 10 * time.Second
+	defaultReadTimeout       = 30 * time.Second
+	defaultWriteTimeout      = 60 * time.Second
+	defaultIdleTimeout       = 120 * time.Second
+	defaultShutdownTimeout   = 30 * time.Second
+)
+
+// Server wraps an http.Server with the time allowed for draining in-flight
+// requests on shutdown and, with TLS, a plain HTTP server redirecting to it.
+type Server struct {
+	http            *http.Server
+	redirect        *http.Server
+	shutdownTimeout time.Duration
+}
+
+// New creates a server listening on server.host and server.port with the
+// timeouts and TLS settings from the server section of config. cache keeps
+// the certificates obtained via ACME, if server.tls.acme.enabled is set.
+func New(handler http.Handler, config *viper.Viper, cache autocert.Cache) (*Server, error) {
+	duration := func(key string, def time.Duration) time.Duration {
+		if config.IsSet(key) {
+			return config.GetDuration(key)
+		}
+		return def
+	}
+	s := &Server{
+		http: &http.Server{
+			Addr:              net.JoinHostPort(config.GetString("server.host"), config.GetString("server.port")),
+			Handler:           handler,
+			ReadHeaderTimeout: duration("server.read_header_timeout", defaultReadHeaderTimeout),
+			ReadTimeout:       duration("server.read_timeout", defaultReadTimeout),
+			WriteTimeout:      duration("server.write_timeout", defaultWriteTimeout),
+			IdleTimeout:       duration("server.idle_timeout", defaultIdleTimeout),
+		},
+		shutdownTimeout: duration("server.shutdown_timeout", defaultShutdownTimeout),
+	}
+	if config.GetBool("server.tls.enabled") {
+		if err := s.configureTLS(config, cache); err != nil {
+			return nil, fmt.Errorf("server.tls: %w", err)
+		}
+	}
+	return s, nil
+}
+
+func (s *Server) Addr() string {
+	return s.http.Addr
+}
+
+// Run serves until ctx is done and then drains in-flight requests for up to
+// the shutdown timeout. It returns an error if the server could not listen or
+// did not shut down cleanly.
+func (s *Server) Run(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.http.Addr)
+	if err != nil {
+		return err
+	}
+	if s.redirect == nil {
+		return s.Serve(ctx, ln)
+	}
+	rln, err := net.Listen("tcp", s.redirect.Addr)
+	if err != nil {
+		ln.Close()
+		return err
+	}
+	return s.serve(ctx, []*http.Server{s.http, s.redirect}, []net.Listener{ln, rln})
+}
+
+// Serve is like Run but accepts connections on ln and does not redirect
+// plain HTTP.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	return s.serve(ctx, []*http.Server{s.http}, []net.Listener{ln})
+}
+
+func (s *Server) serve(ctx context.Context, srvs []*http.Server, lns []net.Listener) error {
+	errc := make(chan error, len(srvs))
+	for i := range srvs {
+		go func(srv *http.Server, ln net.Listener) {
+			if srv.TLSConfig != nil {
+				// The certificates are in the TLS config already.
+				errc <- srv.ServeTLS(ln, "", "")
+				return
+			}
+			errc <- srv.Serve(ln)
+		}(srvs[i], lns[i])
+	}
+
+	var err error
+	pending := len(srvs)
+	select {
+	case err = <-errc:
+		pending--
+	case <-ctx.Done():
+		slog.Info("shutting down, draining connections", "timeout", s.shutdownTimeout)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+	for _, srv := range srvs {
+		if serr := srv.Shutdown(shutdownCtx); serr != nil && err == nil {
+			err = fmt.Errorf("shutdown: %w", serr)
+		}
+	}
+	for ; pending > 0; pending-- {
+		if serr := <-errc; !errors.Is(serr, http.ErrServerClosed) && err == nil {
+			err = serr
+		}
+	}
+	return err
+}
+
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// WaitFor pings db until it answers, trying up to attempts times with delay
+// in between. It gives up early when ctx is done.
+func WaitFor(ctx context.Context, db Pinger, attempts int, delay time.Duration) error {
+	if attempts < 1 {
+		attempts = 1
+	}
+	var err error
+	for i := 1; i <= attempts; i++ {
+		err = db.PingContext(ctx)
+		if err == nil {
+			return nil
+		}
+		if i == attempts {
+			break
+		}
+		slog.Warn("database not reachable", "attempt", i, "attempts", attempts, "error", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+	return fmt.Errorf("database not reachable after %d attempts: %w", attempts, err)
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	config := viper.New()
+	config.Set("server.host", "localhost")
+	config.Set("server.port", 5000)
+	config.Set("server.write_timeout", "5s")
+
+	s, err := New(http.NotFoundHandler(), config, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "localhost:5000", s.Addr())
+	assert.Equal(t, 5*time.Second, s.http.WriteTimeout)
+	assert.Equal(t, defaultReadTimeout, s.http.ReadTimeout)
+	assert.Equal(t, defaultShutdownTimeout, s.shutdownTimeout)
+}
+
+func TestServe(t *testing.T) {
+	t.Run("Drains In-Flight Requests", func(t *testing.T) {
+		started := make(chan struct{})
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			time.Sleep(100 * time.Millisecond)
+			io.WriteString(w, "done")
+		})
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := &Server{http: &http.Server{Handler: handler}, shutdownTimeout: time.Second}
+		ctx, cancel := context.WithCancel(context.Background())
+		errc := make(chan error, 1)
+		go func() {
+			errc <- s.Serve(ctx, ln)
+		}()
+
+		respc := make(chan string, 1)
+		go func() {
+			resp, err := http.Get("http://" + ln.Addr().String())
+			if err != nil {
+				respc <- err.Error()
+				return
+			}
+			defer resp.Body.Close()
+			b, _ := io.ReadAll(resp.Body)
+			respc <- string(b)
+		}()
+
+		<-started
+		cancel()
+		assert.Equal(t, "done", <-respc)
+		assert.NoError(t, <-errc)
+	})
+
+	t.Run("Listen Failure", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ln.Close()
+		s := &Server{http: &http.Server{Addr: ln.Addr().String()}, shutdownTimeout: time.Second}
+		assert.Error(t, s.Run(context.Background()))
+	})
+}
+
+type pinger struct {
+	failures int
+	calls    int
+}
+
+func (p *pinger) PingContext(ctx context.Context) error {
+	p.calls++
+	if p.calls <= p.failures {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func TestWaitFor(t *testing.T) {
+	p := &pinger{failures: 2}
+	assert.NoError(t, WaitFor(context.Background(), p, 3, time.Millisecond))
+	assert.Equal(t, 3, p.calls)
+
+	p = &pinger{failures: 5}
+	err := WaitFor(context.Background(), p, 3, time.Millisecond)
+	assert.ErrorContains(t, err, "after 3 attempts")
+	assert.Equal(t, 3, p.calls)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = WaitFor(ctx, &pinger{failures: 5}, 3, time.Hour)
+	assert.ErrorIs(t, err, context.Canceled)
+}
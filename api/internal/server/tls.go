@@ -0,0 +1,87 @@
+package server
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// configureTLS serves s over TLS with the certificate in server.tls.cert_file
+// and key_file, or with certificates for server.tls.acme.hosts obtained from
+// Let's Encrypt. If server.tls.redirect_addr is set, plain HTTP requests to it
+// are redirected to HTTPS and ACME HTTP-01 challenges are answered there.
+func (s *Server) configureTLS(config *viper.Viper, cache autocert.Cache) error {
+	minVersion := uint16(tls.VersionTLS12)
+	if config.IsSet("server.tls.min_version") {
+		v, ok := tlsVersions[config.GetString("server.tls.min_version")]
+		if !ok {
+			return fmt.Errorf("min_version %q not supported, use 1.2 or 1.3", config.GetString("server.tls.min_version"))
+		}
+		minVersion = v
+	}
+
+	redirect := http.Handler(redirectHandler(s.http.Addr))
+	if config.GetBool("server.tls.acme.enabled") {
+		hosts := config.GetStringSlice("server.tls.acme.hosts")
+		if len(hosts) == 0 {
+			return errors.New("acme.hosts must list the host names to obtain certificates for")
+		}
+		if cache == nil {
+			return errors.New("acme requires a certificate cache")
+		}
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(hosts...),
+			Cache:      cache,
+			Email:      config.GetString("server.tls.acme.email"),
+		}
+		s.http.TLSConfig = manager.TLSConfig()
+		redirect = manager.HTTPHandler(redirect)
+	} else {
+		cert, err := tls.LoadX509KeyPair(config.GetString("server.tls.cert_file"), config.GetString("server.tls.key_file"))
+		if err != nil {
+			return err
+		}
+		s.http.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+	}
+	s.http.TLSConfig.MinVersion = minVersion
+
+	if addr := config.GetString("server.tls.redirect_addr"); addr != "" {
+		s.redirect = &http.Server{
+			Addr:              addr,
+			Handler:           redirect,
+			ReadHeaderTimeout: s.http.ReadHeaderTimeout,
+			ReadTimeout:       s.http.ReadTimeout,
+			WriteTimeout:      s.http.WriteTimeout,
+			IdleTimeout:       s.http.IdleTimeout,
+		}
+	}
+	return nil
+}
+
+// redirectHandler redirects to the same URL over HTTPS on the port of
+// httpsAddr.
+func redirectHandler(httpsAddr string) http.HandlerFunc {
+	_, port, _ := net.SplitHostPort(httpsAddr)
+	return func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(r.Host); err == nil {
+			host = h
+		}
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+		target := "https://" + host + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusPermanentRedirect)
+	}
+}
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+// selfSigned writes a certificate for localhost and its key to dir.
+func selfSigned(t *testing.T, dir string) (certFile, keyFile string, pool *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	pool = x509.NewCertPool()
+	pool.AddCert(cert)
+	return certFile, keyFile, pool
+}
+
+func TestTLS(t *testing.T) {
+	certFile, keyFile, pool := selfSigned(t, t.TempDir())
+	config := viper.New()
+	config.Set("server.host", "127.0.0.1")
+	config.Set("server.port", 0)
+	config.Set("server.tls.enabled", true)
+	config.Set("server.tls.cert_file", certFile)
+	config.Set("server.tls.key_file", keyFile)
+	config.Set("server.tls.min_version", "1.3")
+
+	s, err := New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.Proto)
+	}), config, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() {
+		errc <- s.Serve(ctx, ln)
+	}()
+	defer func() {
+		cancel()
+		assert.NoError(t, <-errc)
+	}()
+	url := "https://" + ln.Addr().String()
+
+	t.Run("Trusted Client", func(t *testing.T) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}, ForceAttemptHTTP2: true}}
+		resp, err := client.Get(url)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "HTTP/2.0", string(body))
+		assert.Equal(t, uint16(tls.VersionTLS13), resp.TLS.Version)
+	})
+
+	t.Run("Below Min Version", func(t *testing.T) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, MaxVersion: tls.VersionTLS12}}}
+		_, err := client.Get(url)
+		assert.Error(t, err)
+	})
+
+	t.Run("Untrusted Client", func(t *testing.T) {
+		_, err := http.Get(url)
+		assert.Error(t, err)
+	})
+}
+
+func TestTLSConfigErrors(t *testing.T) {
+	config := viper.New()
+	config.Set("server.tls.enabled", true)
+	config.Set("server.tls.cert_file", "/nonexistent/cert.pem")
+	_, err := New(http.NotFoundHandler(), config, nil)
+	assert.Error(t, err)
+
+	config.Set("server.tls.acme.enabled", true)
+	config.Set("server.tls.acme.hosts", []string{"calendar.example.com"})
+	_, err = New(http.NotFoundHandler(), config, nil)
+	assert.ErrorContains(t, err, "cache")
+
+	config.Set("server.tls.min_version", "1.0")
+	_, err = New(http.NotFoundHandler(), config, nil)
+	assert.ErrorContains(t, err, "min_version")
+}
+
+func TestRedirectHandler(t *testing.T) {
+	tests := []struct {
+		httpsAddr string
+		host      string
+		want      string
+	}{
+		{":443", "calendar.example.com", "https://calendar.example.com/api/events?week=3"},
+		{":443", "calendar.example.com:80", "https://calendar.example.com/api/events?week=3"},
+		{"localhost:8443", "localhost:8080", "https://localhost:8443/api/events?week=3"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/api/events?week=3", nil)
+		req.Host = tt.host
+		w := httptest.NewRecorder()
+		redirectHandler(tt.httpsAddr)(w, req)
+		assert.Equal(t, http.StatusPermanentRedirect, w.Code)
+		assert.Equal(t, tt.want, w.Header().Get("Location"))
+	}
+}
@@ -0,0 +1,156 @@
+package postgres
+
+import (
+	"api/internal/models"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const attachmentColumns = "a.id, a.uuid, e.uuid, a.name, a.content_type, a.size, a.created_at"
+
+type attachmentAccess struct {
+	db *sql.DB
+}
+
+func NewAttachmentAccess(db *sql.DB) *attachmentAccess {
+	return &attachmentAccess{
+		db: db,
+	}
+}
+
+func (aa *attachmentAccess) GetByEvent(eventUUID string) ([]models.Attachment, error) {
+	query := `
+SELECT ` + attachmentColumns + `
+FROM attachments a
+JOIN events e ON e.id = a.event_id
+WHERE e.uuid = $1
+AND e.deleted_at IS NULL
+ORDER BY a.created_at, a.id;`
+	rows, err := aa.db.Query(query, eventUUID)
+	if err != nil {
+		return nil, storageError(err)
+	}
+	defer rows.Close()
+	atts := []models.Attachment{}
+	for rows.Next() {
+		var a models.Attachment
+		err = scanAttachment(rows, &a)
+		if err != nil {
+			return nil, storageError(err)
+		}
+		atts = append(atts, a)
+	}
+	if len(atts) == 0 {
+		if err := checkEvent(aa.db, eventUUID); err != nil {
+			return nil, err
+		}
+	}
+	return atts, nil
+}
+
+func (aa *attachmentAccess) Get(eventUUID, uuid string) (*models.Attachment, error) {
+	query := `
+SELECT ` + attachmentColumns + `
+FROM attachments a
+JOIN events e ON e.id = a.event_id
+WHERE e.uuid = $1
+AND a.uuid = $2
+AND e.deleted_at IS NULL;`
+	var a models.Attachment
+	err := scanAttachment(aa.db.QueryRow(query, eventUUID, uuid), &a)
+	if err != nil {
+		return nil, attachmentError(err)
+	}
+	return &a, nil
+}
+
+func (aa *attachmentAccess) Create(a *models.Attachment) error {
+	query := `
+WITH evt AS (` + touchEvents("uuid = $5\nAND deleted_at IS NULL") + `
+RETURNING id
+)
+INSERT INTO attachments (uuid, event_id, name, content_type, size)
+SELECT $1, id, $2, $3, $4
+FROM evt
+RETURNING id, created_at;`
+	if a.UUID == "" {
+		a.UUID = uuid.New().String()
+	}
+	err := aa.db.QueryRow(query, a.UUID, a.Name, a.ContentType, a.Size, a.EventUUID).Scan(&a.ID, &a.CreatedAt)
+	if err != nil {
+		return storageError(err)
+	}
+	return nil
+}
+
+func (aa *attachmentAccess) Delete(eventUUID, uuid string) error {
+	query := `
+WITH a AS (
+DELETE FROM attachments a
+USING events e
+WHERE e.id = a.event_id
+AND e.uuid = $1
+AND a.uuid = $2
+AND e.deleted_at IS NULL
+RETURNING a.id, a.event_id
+), evt AS (` + touchEvents("id IN (SELECT event_id FROM a)") + `
+)
+SELECT id FROM a;`
+	var id int
+	err := aa.db.QueryRow(query, eventUUID, uuid).Scan(&id)
+	if err != nil {
+		return attachmentError(err)
+	}
+	return nil
+}
+
+func (aa *attachmentAccess) Trashed(before time.Time) ([]string, error) {
+	query := `
+SELECT a.uuid
+FROM attachments a
+JOIN events e ON e.id = a.event_id
+WHERE e.deleted_at < $1
+ORDER BY a.id;`
+	return aa.uuids(query, before)
+}
+
+func (aa *attachmentAccess) Existing(uuids []string) ([]string, error) {
+	query := `
+SELECT uuid
+FROM attachments
+WHERE uuid = ANY($1)
+ORDER BY id;`
+	return aa.uuids(query, pq.Array(uuids))
+}
+
+func (aa *attachmentAccess) uuids(query string, queryArgs ...any) ([]string, error) {
+	rows, err := aa.db.Query(query, queryArgs...)
+	if err != nil {
+		return nil, storageError(err)
+	}
+	defer rows.Close()
+	uuids := []string{}
+	for rows.Next() {
+		var uuid string
+		if err := rows.Scan(&uuid); err != nil {
+			return nil, storageError(err)
+		}
+		uuids = append(uuids, uuid)
+	}
+	return uuids, storageError(rows.Err())
+}
+
+func scanAttachment(s scanner, a *models.Attachment) error {
+	return s.Scan(&a.ID, &a.UUID, &a.EventUUID, &a.Name, &a.ContentType, &a.Size, &a.CreatedAt)
+}
+
+func attachmentError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return &models.StorageError{Kind: models.ErrNotFound, Code: "not_found", Detail: "attachment does not exist", Err: err}
+	}
+	return storageError(err)
+}
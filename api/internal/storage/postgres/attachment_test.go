@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"api/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttachmentGetByEvent(t *testing.T) {
+	reloadTestDatabase()
+
+	atts, err := aa.GetByEvent("123e4567-e89b-12d3-a456-426614174000")
+	assert.NoError(t, err)
+	if assert.Len(t, atts, 1) {
+		assert.Equal(t, "agenda.pdf", atts[0].Name)
+		assert.Equal(t, "123e4567-e89b-12d3-a456-426614174000", atts[0].EventUUID)
+	}
+
+	atts, err = aa.GetByEvent("123e4567-e89b-12d3-a456-426614174001")
+	assert.NoError(t, err)
+	assert.Empty(t, atts)
+
+	// Attachments of trashed events are hidden with the event.
+	_, err = aa.GetByEvent("123e4567-e89b-12d3-a456-426614174005")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestAttachmentCreate(t *testing.T) {
+	reloadTestDatabase()
+
+	att := &models.Attachment{
+		EventUUID:   "123e4567-e89b-12d3-a456-426614174001",
+		Name:        "slides.pptx",
+		ContentType: "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+		Size:        4096,
+	}
+	before, err := ea.GetByUUID(att.EventUUID)
+	assert.NoError(t, err)
+	err = aa.Create(att)
+	assert.NoError(t, err)
+	assert.NotZero(t, att.ID)
+	assert.NotEmpty(t, att.UUID)
+	assert.False(t, att.CreatedAt.IsZero())
+
+	evt, err := ea.GetByUUID(att.EventUUID)
+	assert.NoError(t, err)
+	assert.True(t, evt.UpdatedAt.After(before.UpdatedAt))
+	assert.Equal(t, before.Version, evt.Version)
+
+	got, err := aa.Get(att.EventUUID, att.UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, att.Name, got.Name)
+	assert.Equal(t, att.Size, got.Size)
+
+	err = aa.Create(&models.Attachment{EventUUID: "00000000-0000-0000-0000-000000000000", Name: "x", ContentType: "text/plain"})
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestAttachmentDelete(t *testing.T) {
+	reloadTestDatabase()
+
+	err := aa.Delete("123e4567-e89b-12d3-a456-426614174001", "9b2f4c1e-5d6a-4e8b-9c0d-1e2f3a4b5c6d")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+
+	err = aa.Delete("123e4567-e89b-12d3-a456-426614174000", "9b2f4c1e-5d6a-4e8b-9c0d-1e2f3a4b5c6d")
+	assert.NoError(t, err)
+
+	_, err = aa.Get("123e4567-e89b-12d3-a456-426614174000", "9b2f4c1e-5d6a-4e8b-9c0d-1e2f3a4b5c6d")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestAttachmentTrashed(t *testing.T) {
+	reloadTestDatabase()
+
+	uuids, err := aa.Trashed(time.Date(2023, time.September, 1, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Empty(t, uuids)
+
+	before := time.Date(2023, time.October, 1, 0, 0, 0, 0, time.UTC)
+	uuids, err = aa.Trashed(before)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"9b2f4c1e-5d6a-4e8b-9c0d-1e2f3a4b5c6e"}, uuids)
+
+	_, err = ea.Purge(before)
+	assert.NoError(t, err)
+	existing, err := aa.Existing([]string{"9b2f4c1e-5d6a-4e8b-9c0d-1e2f3a4b5c6d", "9b2f4c1e-5d6a-4e8b-9c0d-1e2f3a4b5c6e"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"9b2f4c1e-5d6a-4e8b-9c0d-1e2f3a4b5c6d"}, existing)
+}
@@ -0,0 +1,135 @@
+package postgres
+
+import (
+	"api/internal/models"
+	"database/sql"
+	"errors"
+)
+
+const commentColumns = "c.id, e.uuid, c.author, c.body, c.created_at, c.edited_at"
+
+type commentAccess struct {
+	db *sql.DB
+}
+
+func NewCommentAccess(db *sql.DB) *commentAccess {
+	return &commentAccess{
+		db: db,
+	}
+}
+
+func (ca *commentAccess) GetByEvent(eventUUID string, limit, offset int) ([]models.Comment, error) {
+	query := `
+SELECT ` + commentColumns + `
+FROM comments c
+JOIN events e ON e.id = c.event_id
+WHERE e.uuid = $1
+AND e.deleted_at IS NULL
+ORDER BY c.created_at, c.id
+LIMIT $2 OFFSET $3;`
+	rows, err := ca.db.Query(query, eventUUID, limit, offset)
+	if err != nil {
+		return nil, storageError(err)
+	}
+	defer rows.Close()
+	comments := []models.Comment{}
+	for rows.Next() {
+		var c models.Comment
+		err = scanComment(rows, &c)
+		if err != nil {
+			return nil, storageError(err)
+		}
+		comments = append(comments, c)
+	}
+	if len(comments) == 0 {
+		if err := checkEvent(ca.db, eventUUID); err != nil {
+			return nil, err
+		}
+	}
+	return comments, nil
+}
+
+func (ca *commentAccess) Get(eventUUID string, id int) (*models.Comment, error) {
+	query := `
+SELECT ` + commentColumns + `
+FROM comments c
+JOIN events e ON e.id = c.event_id
+WHERE e.uuid = $1
+AND c.id = $2
+AND e.deleted_at IS NULL;`
+	var c models.Comment
+	err := scanComment(ca.db.QueryRow(query, eventUUID, id), &c)
+	if err != nil {
+		return nil, commentError(err)
+	}
+	return &c, nil
+}
+
+func (ca *commentAccess) Create(c *models.Comment) error {
+	query := `
+WITH evt AS (` + touchEvents("uuid = $3\nAND deleted_at IS NULL") + `
+RETURNING id
+)
+INSERT INTO comments (event_id, author, body)
+SELECT id, $1, $2
+FROM evt
+RETURNING id, created_at;`
+	err := ca.db.QueryRow(query, c.Author, c.Body, c.EventUUID).Scan(&c.ID, &c.CreatedAt)
+	if err != nil {
+		return storageError(err)
+	}
+	return nil
+}
+
+func (ca *commentAccess) Update(c *models.Comment) error {
+	query := `
+WITH c AS (
+UPDATE comments
+SET body = $1,
+edited_at = CURRENT_TIMESTAMP
+FROM events e
+WHERE e.id = comments.event_id
+AND e.uuid = $2
+AND comments.id = $3
+AND e.deleted_at IS NULL
+RETURNING comments.event_id, comments.author, comments.created_at, comments.edited_at
+), evt AS (` + touchEvents("id IN (SELECT event_id FROM c)") + `
+)
+SELECT author, created_at, edited_at FROM c;`
+	err := ca.db.QueryRow(query, c.Body, c.EventUUID, c.ID).Scan(&c.Author, &c.CreatedAt, &c.EditedAt)
+	if err != nil {
+		return commentError(err)
+	}
+	return nil
+}
+
+func (ca *commentAccess) Delete(eventUUID string, id int) error {
+	query := `
+WITH c AS (
+DELETE FROM comments c
+USING events e
+WHERE e.id = c.event_id
+AND e.uuid = $1
+AND c.id = $2
+AND e.deleted_at IS NULL
+RETURNING c.id, c.event_id
+), evt AS (` + touchEvents("id IN (SELECT event_id FROM c)") + `
+)
+SELECT id FROM c;`
+	err := ca.db.QueryRow(query, eventUUID, id).Scan(&id)
+	if err != nil {
+		return commentError(err)
+	}
+	return nil
+}
+
+func scanComment(s scanner, c *models.Comment) error {
+	return s.Scan(&c.ID, &c.EventUUID, &c.Author, &c.Body, &c.CreatedAt, &c.EditedAt)
+}
+
+func commentError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return &models.StorageError{Kind: models.ErrNotFound, Code: "not_found", Detail: "comment does not exist", Err: err}
+	}
+	return storageError(err)
+}
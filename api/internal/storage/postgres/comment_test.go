@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"api/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommentGetByEvent(t *testing.T) {
+	reloadTestDatabase()
+
+	comments, err := ca.GetByEvent("123e4567-e89b-12d3-a456-426614174000", 2, 0)
+	assert.NoError(t, err)
+	if assert.Len(t, comments, 2) {
+		assert.Equal(t, 1, comments[0].ID)
+		assert.Nil(t, comments[0].EditedAt)
+		assert.NotNil(t, comments[1].EditedAt)
+	}
+
+	comments, err = ca.GetByEvent("123e4567-e89b-12d3-a456-426614174000", 2, 2)
+	assert.NoError(t, err)
+	if assert.Len(t, comments, 1) {
+		assert.Equal(t, 3, comments[0].ID)
+	}
+
+	comments, err = ca.GetByEvent("123e4567-e89b-12d3-a456-426614174001", 50, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, comments)
+
+	_, err = ca.GetByEvent("123e4567-e89b-12d3-a456-426614174005", 50, 0)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestCommentCreate(t *testing.T) {
+	reloadTestDatabase()
+
+	before, err := ea.GetByUUID("123e4567-e89b-12d3-a456-426614174001")
+	assert.NoError(t, err)
+	comment := &models.Comment{EventUUID: "123e4567-e89b-12d3-a456-426614174001", Author: "carol", Body: "Agenda attached."}
+	err = ca.Create(comment)
+	assert.NoError(t, err)
+	assert.NotZero(t, comment.ID)
+	assert.False(t, comment.CreatedAt.IsZero())
+
+	// The comment is a change of the event, but writes conditional on its
+	// version still succeed.
+	evt, err := ea.GetByUUID(comment.EventUUID)
+	assert.NoError(t, err)
+	assert.True(t, evt.UpdatedAt.After(before.UpdatedAt))
+	assert.Equal(t, before.Version, evt.Version)
+
+	err = ca.Create(&models.Comment{EventUUID: "123e4567-e89b-12d3-a456-426614174005", Author: "carol", Body: "Hello?"})
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestCommentUpdate(t *testing.T) {
+	reloadTestDatabase()
+
+	comment := &models.Comment{ID: 1, EventUUID: "123e4567-e89b-12d3-a456-426614174000", Body: "Please bring the Q4 numbers."}
+	err := ca.Update(comment)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", comment.Author)
+	assert.NotNil(t, comment.EditedAt)
+
+	evt, err := ea.GetByUUID(comment.EventUUID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, evt.Version)
+
+	err = ca.Update(&models.Comment{ID: 1, EventUUID: "123e4567-e89b-12d3-a456-426614174001", Body: "x"})
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestCommentDelete(t *testing.T) {
+	reloadTestDatabase()
+
+	err := ca.Delete("123e4567-e89b-12d3-a456-426614174000", 3)
+	assert.NoError(t, err)
+
+	evt, err := ea.GetByUUID("123e4567-e89b-12d3-a456-426614174000")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, evt.Version)
+
+	_, err = ca.Get("123e4567-e89b-12d3-a456-426614174000", 3)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"api/internal/models"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+const (
+	exclusionViolation = "23P01"
+	uniqueViolation    = "23505"
+	integrityClass     = "23"
+	dataExceptionClass = "22"
+)
+
+func storageError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return &models.StorageError{Kind: models.ErrNotFound, Code: "not_found", Detail: "event does not exist", Err: err}
+	}
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+	switch {
+	case pqErr.Code == exclusionViolation:
+		return &models.StorageError{Kind: models.ErrConflict, Code: pqErr.Constraint, Detail: "event overlaps an existing event", Err: err}
+	case pqErr.Code == uniqueViolation:
+		return &models.StorageError{Kind: models.ErrConflict, Code: "duplicate", Detail: pqErr.Detail, Err: err}
+	case pqErr.Code.Class() == integrityClass:
+		return &models.StorageError{Kind: models.ErrConstraint, Code: pqErr.Code.Name(), Detail: pqErr.Message, Err: err}
+	case pqErr.Code.Class() == dataExceptionClass:
+		return &models.StorageError{Kind: models.ErrValidation, Code: pqErr.Code.Name(), Detail: pqErr.Message, Err: err}
+	}
+	return err
+}
+
+func validationError(err error) error {
+	return &models.StorageError{Kind: models.ErrValidation, Code: "invalid_query", Detail: err.Error()}
+}
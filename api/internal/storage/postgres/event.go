@@ -1,68 +1,137 @@
 package postgres
 
 import (
+	"api/internal/filter"
 	"api/internal/models"
+	"api/internal/search"
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const eventColumns = "id, uuid, title, description, date_from, date_to, tz_from, tz_to, all_day, start_date, end_date, location, url, conference, created_at, updated_at, version, deleted_at"
+
+var eventFieldColumns = map[models.EventField]string{
+	models.ID:              "id",
+	models.UUID:            "uuid",
+	models.Title:           "title",
+	models.Description:     "description",
+	models.DateFrom:        "date_from",
+	models.DateTo:          "date_to",
+	models.TZFrom:          "tz_from",
+	models.TZTo:            "tz_to",
+	models.AllDay:          "all_day",
+	models.StartDate:       "start_date",
+	models.EndDate:         "end_date",
+	models.EventLocation:   "location",
+	models.URL:             "url",
+	models.EventConference: "conference",
+	models.CreatedAt:       "created_at",
+	models.UpdatedAt:       "updated_at",
+}
+
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
 type eventAccess struct {
-	db *sql.DB
+	db    querier
+	conn  *sql.DB
+	tx    *sql.Tx
+	depth int
+	ctx   context.Context
 }
 
 func NewEventAccess(db *sql.DB) *eventAccess {
 	return &eventAccess{
-		db: db,
+		db:   db,
+		conn: db,
+		ctx:  context.Background(),
+	}
+}
+
+func (ea *eventAccess) WithContext(ctx context.Context) models.EventAccess {
+	bound := *ea
+	bound.ctx = ctx
+	return &bound
+}
+
+// InTx runs fn in a transaction, or in a savepoint if ea is already bound to
+// one, and commits unless fn fails.
+func (ea *eventAccess) InTx(fn func(tx models.EventAccess) error) (err error) {
+	if ea.tx == nil {
+		tx, err := ea.conn.BeginTx(ea.ctx, nil)
+		if err != nil {
+			return storageError(err)
+		}
+		defer func() {
+			if p := recover(); p != nil {
+				tx.Rollback()
+				panic(p)
+			}
+		}()
+		if err := fn(&eventAccess{db: tx, tx: tx, ctx: ea.ctx}); err != nil {
+			tx.Rollback()
+			return err
+		}
+		return storageError(tx.Commit())
+	}
+
+	savepoint := fmt.Sprintf("sp_%d", ea.depth+1)
+	if _, err := ea.exec("SAVEPOINT " + savepoint + ";"); err != nil {
+		return storageError(err)
 	}
+	defer func() {
+		if p := recover(); p != nil {
+			ea.exec("ROLLBACK TO SAVEPOINT " + savepoint + ";")
+			panic(p)
+		}
+	}()
+	if err := fn(&eventAccess{db: ea.tx, tx: ea.tx, depth: ea.depth + 1, ctx: ea.ctx}); err != nil {
+		ea.exec("ROLLBACK TO SAVEPOINT " + savepoint + ";")
+		return err
+	}
+	_, err = ea.exec("RELEASE SAVEPOINT " + savepoint + ";")
+	return storageError(err)
 }
 
 func (ea *eventAccess) GetAll() ([]models.Event, error) {
-	query := `SELECT id, uuid, title, description, date_from, date_to, created_at FROM events;`
-	rows, err := ea.db.Query(query)
+	query := `SELECT ` + eventColumns + ` FROM events WHERE deleted_at IS NULL;`
+	rows, err := ea.query(query)
 	if err != nil {
-		return nil, err
+		return nil, storageError(err)
 	}
 	defer rows.Close()
 	var evts []models.Event
 	for rows.Next() {
 		var evt models.Event
-		err = rows.Scan(&evt.ID, &evt.UUID, &evt.Title, &evt.Description, &evt.DateFrom, &evt.DateTo, &evt.CreatedAt)
+		err = scanEvent(rows, &evt)
 		if err != nil {
 			return nil, err
 		}
 		evts = append(evts, evt)
 	}
-	return evts, nil
+	return evts, ea.loadTags(eventPtrs(evts)...)
 }
 
-func (ea *eventAccess) GetByFilter(startDate, endDate time.Time, sortField models.EventField, sortOrder models.SortOrder, limit int) ([]models.Event, error) {
+func (ea *eventAccess) GetByFilter(startDate, endDate time.Time, where filter.Expr, sortField models.EventField, sortOrder models.SortOrder, limit int) ([]models.Event, error) {
 	var (
 		evts      []models.Event
 		queryArgs []any
 	)
 
-	var sortFieldName string
-	switch sortField {
-	case models.ID:
-		sortFieldName = "id"
-	case models.UUID:
-		sortFieldName = "uuid"
-	case models.Title:
-		sortFieldName = "title"
-	case models.Description:
-		sortFieldName = "description"
-	case models.DateFrom:
-		sortFieldName = "date_from"
-	case models.DateTo:
-		sortFieldName = "date_to"
-	case models.CreatedAt:
-		sortFieldName = "created_at"
-	default:
-		return evts, fmt.Errorf("sortField %v not supported", sortField)
+	sortFieldName, ok := eventFieldColumns[sortField]
+	if !ok {
+		return evts, validationError(fmt.Errorf("sortField %v not supported", sortField))
 	}
 
 	var sortOrderName string
@@ -72,24 +141,28 @@ func (ea *eventAccess) GetByFilter(startDate, endDate time.Time, sortField model
 	case models.Desc:
 		sortOrderName = "DESC"
 	default:
-		return evts, fmt.Errorf("sortOrder %v not supported", sortOrder)
+		return evts, validationError(fmt.Errorf("sortOrder %v not supported", sortOrder))
 	}
 
 	var b strings.Builder
 
-	b.WriteString("SELECT id, uuid, title, description, date_from, date_to, created_at")
+	b.WriteString("SELECT " + eventColumns)
 	b.WriteString("\nFROM events")
 
-	if !startDate.IsZero() && !endDate.IsZero() {
-		b.WriteString("\nWHERE ($1, $2) OVERLAPS (date_from, date_to)")
-		queryArgs = append(queryArgs, startDate, endDate)
-	} else if !startDate.IsZero() {
-		b.WriteString("\nWHERE date_to > $1")
-		queryArgs = append(queryArgs, startDate)
-	} else if !endDate.IsZero() {
-		b.WriteString("\nWHERE date_from < $1")
-		queryArgs = append(queryArgs, endDate)
+	conds := []string{"deleted_at IS NULL"}
+	cond, queryArgs := windowCond(startDate, endDate, queryArgs)
+	if cond != "" {
+		conds = append(conds, cond)
 	}
+	if where != nil {
+		var err error
+		cond, queryArgs, err = filterCond(where, queryArgs)
+		if err != nil {
+			return evts, validationError(err)
+		}
+		conds = append(conds, cond)
+	}
+	b.WriteString("\nWHERE " + strings.Join(conds, " AND "))
 	b.WriteString(fmt.Sprintf("\nORDER BY %s %s", sortFieldName, sortOrderName))
 
 	if limit != 0 {
@@ -99,62 +172,542 @@ func (ea *eventAccess) GetByFilter(startDate, endDate time.Time, sortField model
 	b.WriteString(";")
 	query := b.String()
 
-	rows, err := ea.db.Query(query, queryArgs...)
+	rows, err := ea.query(query, queryArgs...)
 	if err != nil {
-		return nil, err
+		return nil, storageError(err)
 	}
 	defer rows.Close()
 	for rows.Next() {
 		var evt models.Event
-		err = rows.Scan(&evt.ID, &evt.UUID, &evt.Title, &evt.Description, &evt.DateFrom, &evt.DateTo, &evt.CreatedAt)
+		err = scanEvent(rows, &evt)
 		if err != nil {
 			return nil, err
 		}
 		evts = append(evts, evt)
 	}
-	return evts, nil
+	return evts, ea.loadTags(eventPtrs(evts)...)
+}
+
+func (ea *eventAccess) Stat(startDate, endDate time.Time, where filter.Expr) (models.ListStat, error) {
+	var (
+		stat      models.ListStat
+		queryArgs []any
+	)
+
+	var b strings.Builder
+
+	b.WriteString("SELECT count(*), coalesce(max(updated_at), 'epoch'),")
+	b.WriteString("\ncoalesce(md5(string_agg(id || ':' || version || ':' || updated_at, ',' ORDER BY id)), ''),")
+	b.WriteString("\n(SELECT coalesce(md5(string_agg(id || ':' || name || ':' || color, ',' ORDER BY id)), '') FROM tags)")
+	b.WriteString("\nFROM events")
+
+	conds := []string{"deleted_at IS NULL"}
+	cond, queryArgs := windowCond(startDate, endDate, queryArgs)
+	if cond != "" {
+		conds = append(conds, cond)
+	}
+	if where != nil {
+		var err error
+		cond, queryArgs, err = filterCond(where, queryArgs)
+		if err != nil {
+			return stat, validationError(err)
+		}
+		conds = append(conds, cond)
+	}
+	b.WriteString("\nWHERE " + strings.Join(conds, " AND "))
+	b.WriteString(";")
+
+	err := ea.queryRow(b.String(), queryArgs...).Scan(&stat.Count, &stat.UpdatedAt, &stat.Events, &stat.Tags)
+	if err != nil {
+		return stat, storageError(err)
+	}
+	return stat, nil
+}
+
+func (ea *eventAccess) Search(q string, startDate, endDate time.Time, limit int) ([]models.SearchResult, error) {
+	var (
+		results   []models.SearchResult
+		queryArgs []any
+	)
+
+	terms := search.Terms(q)
+	if len(terms) == 0 {
+		return results, nil
+	}
+	for i := range terms {
+		terms[i] += ":*"
+	}
+	headlineOpts := fmt.Sprintf(`StartSel="%s", StopSel="%s", MaxWords=%d, MinWords=%d`,
+		search.StartSel, search.StopSel, search.MaxWords, search.MaxWords/2)
+	queryArgs = append(queryArgs, strings.Join(terms, " & "), headlineOpts)
+
+	var b strings.Builder
+
+	b.WriteString("SELECT " + eventColumns + ",")
+	b.WriteString("\nts_rank(search, q) AS rank,")
+	b.WriteString("\nts_headline('english', title || ' ' || description || ' ' || coalesce(location->>'name', ''), q, $2) AS snippet")
+	b.WriteString("\nFROM events, to_tsquery('english', $1) q")
+	b.WriteString("\nWHERE search @@ q")
+	b.WriteString("\nAND deleted_at IS NULL")
+
+	var cond string
+	cond, queryArgs = windowCond(startDate, endDate, queryArgs)
+	if cond != "" {
+		b.WriteString("\nAND " + cond)
+	}
+	b.WriteString("\nORDER BY rank DESC, date_from ASC")
+
+	if limit != 0 {
+		b.WriteString(fmt.Sprintf("\nLIMIT %d", limit))
+	}
+
+	b.WriteString(";")
+	query := b.String()
+
+	rows, err := ea.query(query, queryArgs...)
+	if err != nil {
+		return nil, storageError(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var res models.SearchResult
+		err = scanEvent(rows, &res.Event, &res.Rank, &res.Snippet)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, res)
+	}
+	evts := make([]*models.Event, len(results))
+	for i := range results {
+		evts[i] = &results[i].Event
+	}
+	return results, ea.loadTags(evts...)
 }
 
 func (ea *eventAccess) Create(evt *models.Event) (string, error) {
-	query := `
-INSERT INTO events (uuid, title, description, date_from, date_to)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING uuid;`
+	write := `
+INSERT INTO events (uuid, title, description, date_from, date_to, tz_from, tz_to, all_day, start_date, end_date, location, url, conference)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
 	uuid := uuid.New().String()
-	_, err := ea.db.Exec(query, uuid, evt.Title, evt.Description, evt.DateFrom, evt.DateTo)
-	return uuid, err
+	queryArgs := []any{
+		uuid, evt.Title, evt.Description, evt.DateFrom, evt.DateTo, evt.TZFrom, evt.TZTo, evt.AllDay, evt.StartDate, evt.EndDate,
+		evt.Location, evt.URL, evt.Conference,
+	}
+	query := write + "\nRETURNING version;"
+	if evt.Tags != nil {
+		query, queryArgs = withTags(write, "id, version", "version", queryArgs, evt.Tags)
+	}
+	err := ea.queryRow(query, queryArgs...).Scan(&evt.Version)
+	if err != nil {
+		return "", ea.writeError(err, evt.DateFrom, evt.DateTo, uuid)
+	}
+	return uuid, nil
 }
 
 func (ea *eventAccess) GetByUUID(uuid string) (*models.Event, error) {
 	query := `
-SELECT id, uuid, title, description, date_from, date_to, created_at
+SELECT ` + eventColumns + `
 FROM events
-WHERE uuid = $1;`
+WHERE uuid = $1
+AND deleted_at IS NULL;`
 	var evt models.Event
-	err := ea.db.QueryRow(query, uuid).
-		Scan(&evt.ID, &evt.UUID, &evt.Title, &evt.Description, &evt.DateFrom, &evt.DateTo, &evt.CreatedAt)
+	err := scanEvent(ea.queryRow(query, uuid), &evt)
 	if err != nil {
-		return nil, err
+		return nil, storageError(err)
 	}
-	return &evt, nil
+	return &evt, ea.loadTags(&evt)
 }
 
 func (ea *eventAccess) Update(evt *models.Event) error {
-	query := `
+	write := `
 UPDATE events
 SET title = $1,
 description = $2,
 date_from = $3,
-date_to = $4
-WHERE uuid = $5;`
-	_, err := ea.db.Exec(query, evt.Title, evt.Description, evt.DateFrom, evt.DateTo, evt.UUID)
-	return err
+date_to = $4,
+tz_from = $5,
+tz_to = $6,
+all_day = $7,
+start_date = $8,
+end_date = $9,
+location = $10,
+url = $11,
+conference = $12,
+updated_at = CURRENT_TIMESTAMP,
+version = version + 1
+WHERE uuid = $13
+AND deleted_at IS NULL
+AND ($14 = 0 OR version = $14)`
+	queryArgs := []any{
+		evt.Title, evt.Description, evt.DateFrom, evt.DateTo, evt.TZFrom, evt.TZTo, evt.AllDay, evt.StartDate, evt.EndDate,
+		evt.Location, evt.URL, evt.Conference,
+		evt.UUID, evt.Version,
+	}
+	query := write + "\nRETURNING updated_at, version;"
+	if evt.Tags != nil {
+		query, queryArgs = withTags(write, "id, updated_at, version", "updated_at, version", queryArgs, evt.Tags)
+	}
+	err := ea.queryRow(query, queryArgs...).Scan(&evt.UpdatedAt, &evt.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ea.versionError(evt.UUID, evt.Version)
+	}
+	if err != nil {
+		return ea.writeError(err, evt.DateFrom, evt.DateTo, evt.UUID)
+	}
+	return nil
+}
+
+func (ea *eventAccess) Patch(evt *models.Event, fields []models.EventField) error {
+	var (
+		sets      []string
+		queryArgs []any
+		tags      bool
+	)
+	for _, field := range fields {
+		var value any
+		switch field {
+		case models.Tags:
+			tags = true
+			continue
+		case models.Title:
+			value = evt.Title
+		case models.Description:
+			value = evt.Description
+		case models.DateFrom:
+			value = evt.DateFrom
+		case models.DateTo:
+			value = evt.DateTo
+		case models.TZFrom:
+			value = evt.TZFrom
+		case models.TZTo:
+			value = evt.TZTo
+		case models.AllDay:
+			value = evt.AllDay
+		case models.StartDate:
+			value = evt.StartDate
+		case models.EndDate:
+			value = evt.EndDate
+		case models.EventLocation:
+			value = evt.Location
+		case models.URL:
+			value = evt.URL
+		case models.EventConference:
+			value = evt.Conference
+		default:
+			return validationError(fmt.Errorf("field %v cannot be patched", field))
+		}
+		queryArgs = append(queryArgs, value)
+		sets = append(sets, fmt.Sprintf("%s = $%d", eventFieldColumns[field], len(queryArgs)))
+	}
+	sets = append(sets, "updated_at = CURRENT_TIMESTAMP", "version = version + 1")
+	queryArgs = append(queryArgs, evt.UUID, evt.Version)
+	n := len(queryArgs)
+
+	var b strings.Builder
+
+	b.WriteString("UPDATE events")
+	b.WriteString("\nSET " + strings.Join(sets, ",\n"))
+	b.WriteString(fmt.Sprintf("\nWHERE uuid = $%d", n-1))
+	b.WriteString("\nAND deleted_at IS NULL")
+	b.WriteString(fmt.Sprintf("\nAND ($%d = 0 OR version = $%d)", n, n))
+	write := b.String()
+	query := write + "\nRETURNING " + eventColumns + ";"
+	if tags {
+		query, queryArgs = withTags(write, eventColumns, eventColumns, queryArgs, evt.Tags)
+	}
+
+	version := evt.Version
+	err := scanEvent(ea.queryRow(query, queryArgs...), evt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ea.versionError(evt.UUID, version)
+	}
+	if err != nil {
+		return ea.writeError(err, evt.DateFrom, evt.DateTo, evt.UUID)
+	}
+	return ea.loadTags(evt)
 }
 
-func (ea *eventAccess) Delete(uuid string) error {
+func (ea *eventAccess) Delete(uuid string, version int) error {
+	query := `
+UPDATE events
+SET deleted_at = CURRENT_TIMESTAMP,
+updated_at = CURRENT_TIMESTAMP,
+version = version + 1
+WHERE uuid = $1
+AND deleted_at IS NULL
+AND ($2 = 0 OR version = $2);`
+	res, err := ea.exec(query, uuid, version)
+	if err != nil {
+		return storageError(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return storageError(err)
+	}
+	if n == 0 {
+		return ea.versionError(uuid, version)
+	}
+	return nil
+}
+
+func (ea *eventAccess) GetTrash() ([]models.Event, error) {
+	query := `
+SELECT ` + eventColumns + `
+FROM events
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC;`
+	rows, err := ea.query(query)
+	if err != nil {
+		return nil, storageError(err)
+	}
+	defer rows.Close()
+	var evts []models.Event
+	for rows.Next() {
+		var evt models.Event
+		err = scanEvent(rows, &evt)
+		if err != nil {
+			return nil, err
+		}
+		evts = append(evts, evt)
+	}
+	return evts, ea.loadTags(eventPtrs(evts)...)
+}
+
+func (ea *eventAccess) Restore(uuid string) (*models.Event, error) {
+	query := `
+UPDATE events
+SET deleted_at = NULL,
+updated_at = CURRENT_TIMESTAMP,
+version = version + 1
+WHERE uuid = $1
+AND deleted_at IS NOT NULL
+RETURNING ` + eventColumns + `;`
+	var evt models.Event
+	err := scanEvent(ea.queryRow(query, uuid), &evt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &models.StorageError{Kind: models.ErrNotFound, Code: "not_found", Detail: "event is not in the trash", Err: err}
+	}
+	if err != nil {
+		var trashed models.Event
+		if scanEvent(ea.queryRow(`SELECT `+eventColumns+` FROM events WHERE uuid = $1;`, uuid), &trashed) != nil {
+			return nil, storageError(err)
+		}
+		return nil, ea.writeError(err, trashed.DateFrom, trashed.DateTo, uuid)
+	}
+	return &evt, ea.loadTags(&evt)
+}
+
+func (ea *eventAccess) Purge(before time.Time) (int64, error) {
 	query := `
 DELETE FROM events
-WHERE uuid = $1;`
-	_, err := ea.db.Exec(query, uuid)
-	return err
+WHERE deleted_at < $1;`
+	res, err := ea.exec(query, before)
+	if err != nil {
+		return 0, storageError(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, storageError(err)
+	}
+	return n, nil
+}
+
+// versionError explains why a write conditional on version matched no row.
+func (ea *eventAccess) versionError(uuid string, version int) error {
+	var current int
+	err := ea.queryRow(`SELECT version FROM events WHERE uuid = $1 AND deleted_at IS NULL;`, uuid).Scan(&current)
+	if err != nil {
+		return storageError(err)
+	}
+	return &models.StorageError{
+		Kind:   models.ErrPrecondition,
+		Code:   "version_mismatch",
+		Detail: fmt.Sprintf("event is at version %d, not %d", current, version),
+	}
+}
+
+// writeError translates an error of an INSERT or UPDATE of the event uuid
+// spanning dateFrom to dateTo. For overlap violations it looks up the events
+// that are in the way.
+func (ea *eventAccess) writeError(err error, dateFrom, dateTo time.Time, uuid string) error {
+	err = storageError(err)
+	var serr *models.StorageError
+	if !errors.As(err, &serr) || serr.Code != "event_overlap" || ea.tx != nil {
+		// Within a transaction the failed statement has aborted it, so the
+		// lookup below could not run anyway.
+		return err
+	}
+	query := `
+SELECT uuid, title
+FROM events
+WHERE tstzrange(date_from, date_to, '[)') && tstzrange($1, $2, '[)')
+AND uuid <> $3
+AND NOT all_day
+AND deleted_at IS NULL
+ORDER BY date_from;`
+	rows, qerr := ea.query(query, dateFrom, dateTo, uuid)
+	if qerr != nil {
+		return err
+	}
+	defer rows.Close()
+	var titles []string
+	for rows.Next() {
+		var ref models.EventRef
+		if rows.Scan(&ref.UUID, &ref.Title) != nil {
+			return err
+		}
+		serr.Conflicts = append(serr.Conflicts, ref)
+		titles = append(titles, strconv.Quote(ref.Title))
+	}
+	if len(titles) > 0 {
+		serr.Detail = "event overlaps " + strings.Join(titles, ", ")
+	}
+	return serr
+}
+
+// withTags wraps write, the INSERT or UPDATE of a single event without a
+// RETURNING clause, so that it also replaces the links of the event to tags.
+// The write returns the given columns, which must include the event id, and
+// the query selects columns from them. Doing both in one statement keeps them
+// atomic without a transaction.
+func withTags(write, returning, columns string, queryArgs []any, tags []models.Tag) (string, []any) {
+	ids := make([]int64, len(tags))
+	for i, tag := range tags {
+		ids[i] = int64(tag.ID)
+	}
+	queryArgs = append(queryArgs, pq.Array(ids))
+	n := len(queryArgs)
+
+	var b strings.Builder
+
+	b.WriteString("WITH evt AS (\n" + strings.TrimSpace(write))
+	b.WriteString("\nRETURNING " + returning)
+	b.WriteString("\n),")
+	b.WriteString("\nunlinked AS (")
+	b.WriteString("\nDELETE FROM event_tags")
+	b.WriteString("\nWHERE event_id IN (SELECT id FROM evt)")
+	b.WriteString(fmt.Sprintf("\nAND tag_id <> ALL($%d)", n))
+	b.WriteString("\n),")
+	b.WriteString("\nlinked AS (")
+	b.WriteString("\nINSERT INTO event_tags (event_id, tag_id)")
+	b.WriteString(fmt.Sprintf("\nSELECT evt.id, unnest($%d::integer[]) FROM evt", n))
+	b.WriteString("\nON CONFLICT DO NOTHING")
+	b.WriteString("\n)")
+	b.WriteString("\nSELECT " + columns + " FROM evt")
+	b.WriteString(";")
+	return b.String(), queryArgs
+}
+
+// loadTags sets the tags of evts, sorted by name.
+func (ea *eventAccess) loadTags(evts ...*models.Event) error {
+	if len(evts) == 0 {
+		return nil
+	}
+	byID := make(map[int]*models.Event, len(evts))
+	ids := make([]int64, len(evts))
+	for i, evt := range evts {
+		evt.Tags = []models.Tag{}
+		byID[evt.ID] = evt
+		ids[i] = int64(evt.ID)
+	}
+	query := `
+SELECT et.event_id, t.id, t.name, t.color
+FROM event_tags et
+JOIN tags t ON t.id = et.tag_id
+WHERE et.event_id = ANY($1)
+ORDER BY t.name;`
+	rows, err := ea.query(query, pq.Array(ids))
+	if err != nil {
+		return storageError(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			eventID int
+			tag     models.Tag
+		)
+		err = rows.Scan(&eventID, &tag.ID, &tag.Name, &tag.Color)
+		if err != nil {
+			return storageError(err)
+		}
+		evt := byID[eventID]
+		evt.Tags = append(evt.Tags, tag)
+	}
+	return storageError(rows.Err())
+}
+
+func eventPtrs(evts []models.Event) []*models.Event {
+	ptrs := make([]*models.Event, len(evts))
+	for i := range evts {
+		ptrs[i] = &evts[i]
+	}
+	return ptrs
+}
+
+// touchEvents marks the events matching where as changed. Writes to what
+// belongs to an event, like its comments and attachments, run it in the same
+// statement, so that Stat sees them and the events_notify trigger announces
+// them like writes of the event itself. The version is left alone, as it is
+// what writes of the event are conditional on.
+func touchEvents(where string) string {
+	return `
+UPDATE events
+SET updated_at = CURRENT_TIMESTAMP
+WHERE ` + where
+}
+
+// checkEvent returns a not found error if there is no event with uuid outside
+// the trash. It tells an event without comments or attachments from a missing
+// one.
+func checkEvent(db *sql.DB, uuid string) error {
+	var id int
+	err := db.QueryRow(`SELECT id FROM events WHERE uuid = $1 AND deleted_at IS NULL;`, uuid).Scan(&id)
+	if err != nil {
+		return storageError(err)
+	}
+	return nil
+}
+
+// windowCond matches timed events by instant and all-day events by the local
+// dates the window covers in the locations of startDate and endDate.
+func windowCond(startDate, endDate time.Time, queryArgs []any) (string, []any) {
+	n := len(queryArgs)
+	switch {
+	case !startDate.IsZero() && !endDate.IsZero():
+		return fmt.Sprintf(
+				"(NOT all_day AND ($%d, $%d) OVERLAPS (date_from, date_to) OR all_day AND end_date >= $%d AND start_date < $%d)",
+				n+1, n+2, n+3, n+4,
+			),
+			append(queryArgs, startDate.UTC(), endDate.UTC(), models.DateOf(startDate), endDay(endDate))
+	case !startDate.IsZero():
+		return fmt.Sprintf("(NOT all_day AND date_to > $%d OR all_day AND end_date >= $%d)", n+1, n+2),
+			append(queryArgs, startDate.UTC(), models.DateOf(startDate))
+	case !endDate.IsZero():
+		return fmt.Sprintf("(NOT all_day AND date_from < $%d OR all_day AND start_date < $%d)", n+1, n+2),
+			append(queryArgs, endDate.UTC(), endDay(endDate))
+	}
+	return "", queryArgs
+}
+
+// endDay returns the first local date that lies entirely after the instant t.
+func endDay(t time.Time) models.Date {
+	d := models.DateOf(t)
+	if t.Equal(d.In(t.Location())) {
+		return d
+	}
+	return d.AddDays(1)
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanEvent(s scanner, evt *models.Event, extra ...any) error {
+	dest := []any{&evt.ID, &evt.UUID, &evt.Title, &evt.Description, &evt.DateFrom, &evt.DateTo, &evt.TZFrom, &evt.TZTo, &evt.AllDay, &evt.StartDate, &evt.EndDate, &evt.Location, &evt.URL, &evt.Conference, &evt.CreatedAt, &evt.UpdatedAt, &evt.Version, &evt.DeletedAt}
+	err := s.Scan(append(dest, extra...)...)
+	if err != nil {
+		return err
+	}
+	evt.Localize()
+	return nil
 }
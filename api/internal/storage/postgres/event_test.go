@@ -1,8 +1,8 @@
 package postgres
 
 import (
+	"api/internal/filter"
 	"api/internal/models"
-	"database/sql"
 	"testing"
 	"time"
 
@@ -26,6 +26,7 @@ func TestGetByFilter(t *testing.T) {
 		events, err := ea.GetByFilter(
 			time.Date(2023, time.October, 1, 0, 0, 0, 0, time.UTC),
 			time.Date(2023, time.October, 5, 15, 0, 0, 0, time.UTC),
+			nil,
 			models.DateFrom,
 			models.Asc,
 			0,
@@ -38,6 +39,7 @@ func TestGetByFilter(t *testing.T) {
 		events, err := ea.GetByFilter(
 			time.Date(2023, time.October, 1, 0, 0, 0, 0, time.UTC),
 			time.Date(2023, time.October, 10, 9, 0, 0, 0, time.UTC),
+			nil,
 			models.DateFrom,
 			models.Asc,
 			0,
@@ -50,6 +52,7 @@ func TestGetByFilter(t *testing.T) {
 		events, err := ea.GetByFilter(
 			time.Date(2023, time.December, 1, 0, 0, 0, 0, time.UTC),
 			time.Date(2023, time.December, 31, 0, 0, 0, 0, time.UTC),
+			nil,
 			models.DateFrom,
 			models.Asc,
 			0,
@@ -59,6 +62,201 @@ func TestGetByFilter(t *testing.T) {
 	})
 }
 
+func TestGetByFilterAllDay(t *testing.T) {
+	reloadTestDatabase()
+
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+	kiritimati, err := time.LoadLocation("Pacific/Kiritimati")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name string
+		day  time.Time
+		want int
+	}{
+		{"Last Day In New York", time.Date(2023, time.October, 26, 0, 0, 0, 0, newYork), 1},
+		{"Day After In New York", time.Date(2023, time.October, 27, 0, 0, 0, 0, newYork), 0},
+		{"First Day In Kiritimati", time.Date(2023, time.October, 25, 0, 0, 0, 0, kiritimati), 1},
+		{"Day After In Kiritimati", time.Date(2023, time.October, 27, 0, 0, 0, 0, kiritimati), 0},
+		{"Day Before In Kiritimati", time.Date(2023, time.October, 24, 0, 0, 0, 0, kiritimati), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := ea.GetByFilter(tt.day, tt.day.AddDate(0, 0, 1), nil, models.DateFrom, models.Asc, 0)
+			assert.NoError(t, err)
+			assert.Len(t, events, tt.want)
+		})
+	}
+
+	t.Run("Does Not Block Timed Events", func(t *testing.T) {
+		start := models.Date{Year: 2023, Month: time.October, Day: 25}
+		evt := &models.Event{
+			Title:       "On Call",
+			Description: "Someone has to",
+			DateFrom:    time.Date(2023, time.October, 25, 9, 0, 0, 0, time.UTC),
+			DateTo:      time.Date(2023, time.October, 25, 17, 0, 0, 0, time.UTC),
+		}
+		_, err := ea.Create(evt)
+		assert.NoError(t, err)
+
+		evt = &models.Event{Title: "Offsite", AllDay: true, StartDate: &start, EndDate: &start}
+		evt.Normalize()
+		uuid, err := ea.Create(evt)
+		assert.NoError(t, err)
+
+		created, err := ea.GetByUUID(uuid)
+		assert.NoError(t, err)
+		assert.True(t, created.AllDay)
+		assert.Equal(t, start, *created.EndDate)
+	})
+}
+
+func TestGetByFilterExpr(t *testing.T) {
+	reloadTestDatabase()
+
+	t.Run("Contains 1", func(t *testing.T) {
+		where, err := filter.Parse(`title~"two" and duration>=2h`)
+		assert.NoError(t, err)
+		events, err := ea.GetByFilter(time.Time{}, time.Time{}, where, models.DateFrom, models.Asc, 0)
+		assert.NoError(t, err)
+		assert.Len(t, events, 1)
+	})
+
+	t.Run("Contains 3", func(t *testing.T) {
+		where, err := filter.Parse(`created_at > 2023-09-02 and not (title = "Event Five" or id = 6)`)
+		assert.NoError(t, err)
+		events, err := ea.GetByFilter(time.Time{}, time.Time{}, where, models.DateFrom, models.Asc, 0)
+		assert.NoError(t, err)
+		assert.Len(t, events, 3)
+	})
+
+	t.Run("Combined With Window", func(t *testing.T) {
+		where, err := filter.Parse(`description~"test"`)
+		assert.NoError(t, err)
+		events, err := ea.GetByFilter(
+			time.Date(2023, time.October, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2023, time.October, 5, 15, 0, 0, 0, time.UTC),
+			where,
+			models.DateFrom,
+			models.Asc,
+			0,
+		)
+		assert.NoError(t, err)
+		assert.Len(t, events, 2)
+	})
+
+	t.Run("Like Wildcards Are Literal", func(t *testing.T) {
+		where, err := filter.Parse(`title~"%"`)
+		assert.NoError(t, err)
+		events, err := ea.GetByFilter(time.Time{}, time.Time{}, where, models.DateFrom, models.Asc, 0)
+		assert.NoError(t, err)
+		assert.Empty(t, events)
+	})
+}
+
+func TestStat(t *testing.T) {
+	reloadTestDatabase()
+
+	start := time.Date(2023, time.October, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2023, time.November, 1, 0, 0, 0, 0, time.UTC)
+	stat, err := ea.Stat(start, end, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, stat.Count)
+	assert.NotEmpty(t, stat.Tags)
+
+	evt, err := ea.GetByUUID("123e4567-e89b-12d3-a456-426614174000")
+	assert.NoError(t, err)
+	evt.Title = "Event One, updated"
+	assert.NoError(t, ea.Update(evt))
+	updated, err := ea.Stat(start, end, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, updated.Count)
+	assert.True(t, updated.UpdatedAt.After(stat.UpdatedAt))
+
+	// A write that started before the update above but committed after it.
+	_, err = ea.exec(`UPDATE events SET version = version + 1, updated_at = $1 WHERE uuid = $2;`,
+		stat.UpdatedAt, "123e4567-e89b-12d3-a456-426614174001")
+	assert.NoError(t, err)
+	late, err := ea.Stat(start, end, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, updated.Count, late.Count)
+	assert.True(t, late.UpdatedAt.Equal(updated.UpdatedAt))
+	assert.NotEqual(t, updated.Events, late.Events)
+
+	assert.NoError(t, ea.Delete("123e4567-e89b-12d3-a456-426614174004", 0))
+	deleted, err := ea.Stat(start, end, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, deleted.Count)
+
+	tag, err := ta.GetByID(1)
+	assert.NoError(t, err)
+	tag.Color = "#000000"
+	assert.NoError(t, ta.Update(tag))
+	recolored, err := ea.Stat(start, end, nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, deleted.Tags, recolored.Tags)
+}
+
+func TestGetByFilterTag(t *testing.T) {
+	reloadTestDatabase()
+
+	tests := []struct {
+		filter string
+		want   int
+	}{
+		{`tag = "Customer"`, 2},
+		{`tag = "Customer" and tag = "Internal"`, 1},
+		{`tag ~ "intern"`, 2},
+		{`tag != "Customer"`, 4},
+		{`tag = "Focus"`, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			where, err := filter.Parse(tt.filter)
+			assert.NoError(t, err)
+			events, err := ea.GetByFilter(time.Time{}, time.Time{}, where, models.DateFrom, models.Asc, 0)
+			assert.NoError(t, err)
+			assert.Len(t, events, tt.want)
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	reloadTestDatabase()
+
+	t.Run("Contains All", func(t *testing.T) {
+		results, err := ea.Search("event", time.Time{}, time.Time{}, 0)
+		assert.NoError(t, err)
+		assert.Len(t, results, 5)
+	})
+
+	t.Run("Prefix Match", func(t *testing.T) {
+		results, err := ea.Search("thir", time.Time{}, time.Time{}, 0)
+		assert.NoError(t, err)
+		assert.Len(t, results, 1)
+		assert.Equal(t, "Event Three", results[0].Title)
+		assert.Contains(t, results[0].Snippet, "<mark>third</mark>")
+	})
+
+	t.Run("Within Window", func(t *testing.T) {
+		results, err := ea.Search(
+			"test event",
+			time.Date(2023, time.October, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2023, time.October, 5, 15, 0, 0, 0, time.UTC),
+			0,
+		)
+		assert.NoError(t, err)
+		assert.Len(t, results, 2)
+	})
+
+	t.Run("Contains None", func(t *testing.T) {
+		results, err := ea.Search("migration", time.Time{}, time.Time{}, 0)
+		assert.NoError(t, err)
+		assert.Empty(t, results)
+	})
+}
+
 func TestCreate(t *testing.T) {
 	reloadTestDatabase()
 
@@ -81,6 +279,130 @@ func TestCreate(t *testing.T) {
 	assert.True(t, evtBefore.DateTo.Equal(evtAfter.DateTo))
 }
 
+func TestCreateTimeZone(t *testing.T) {
+	reloadTestDatabase()
+
+	evt := &models.Event{
+		Title:       "Weekly",
+		Description: "Nine o'clock in Berlin",
+		DateFrom:    time.Date(2023, time.October, 30, 8, 0, 0, 0, time.UTC),
+		DateTo:      time.Date(2023, time.October, 30, 9, 0, 0, 0, time.UTC),
+		TZFrom:      "Europe/Berlin",
+	}
+	evt.Normalize()
+	uuid, err := ea.Create(evt)
+	assert.NoError(t, err)
+
+	created, err := ea.GetByUUID(uuid)
+	assert.NoError(t, err)
+	assert.Equal(t, "Europe/Berlin", created.TZFrom)
+	assert.Equal(t, "Europe/Berlin", created.TZTo)
+	assert.Equal(t, "Europe/Berlin", created.DateFrom.Location().String())
+	assert.Equal(t, 9, created.DateFrom.Hour())
+	assert.True(t, evt.DateFrom.Equal(created.DateFrom))
+}
+
+func TestTags(t *testing.T) {
+	reloadTestDatabase()
+
+	uuid := "123e4567-e89b-12d3-a456-426614174000"
+	evt, err := ea.GetByUUID(uuid)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Tag{{ID: 1, Name: "Customer", Color: "#1e90ff"}}, evt.Tags)
+
+	evt.Tags = []models.Tag{{ID: 3}, {ID: 2}}
+	err = ea.Update(evt)
+	assert.NoError(t, err)
+	evt, err = ea.GetByUUID(uuid)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Focus", "Internal"}, []string{evt.Tags[0].Name, evt.Tags[1].Name})
+
+	evt.Tags = nil
+	evt.Title = "Renamed"
+	err = ea.Update(evt)
+	assert.NoError(t, err)
+	evt, err = ea.GetByUUID(uuid)
+	assert.NoError(t, err)
+	assert.Len(t, evt.Tags, 2)
+
+	evt.Tags = []models.Tag{}
+	err = ea.Patch(evt, []models.EventField{models.Tags})
+	assert.NoError(t, err)
+	assert.Empty(t, evt.Tags)
+
+	evt.Tags = []models.Tag{{ID: 99}}
+	err = ea.Patch(evt, []models.EventField{models.Tags})
+	assert.ErrorIs(t, err, models.ErrConstraint)
+
+	_, err = ea.Create(&models.Event{
+		Title:       "Tagged",
+		Description: "Created with tags",
+		DateFrom:    time.Date(2023, time.January, 1, 9, 0, 0, 0, time.UTC),
+		DateTo:      time.Date(2023, time.January, 1, 10, 0, 0, 0, time.UTC),
+		Tags:        []models.Tag{{ID: 1}},
+	})
+	assert.NoError(t, err)
+}
+
+func TestCreateLocation(t *testing.T) {
+	reloadTestDatabase()
+
+	lat, lng := 52.52, 13.405
+	evt := &models.Event{
+		Title:       "Offsite",
+		Description: "Planning for next year",
+		DateFrom:    time.Date(2023, time.November, 6, 9, 0, 0, 0, time.UTC),
+		DateTo:      time.Date(2023, time.November, 6, 17, 0, 0, 0, time.UTC),
+		Location:    &models.Location{Name: "Alexanderplatz", Latitude: &lat, Longitude: &lng},
+		URL:         "https://example.com/offsite",
+		Conference:  &models.Conference{Provider: "Jitsi", JoinURL: "https://meet.jit.si/offsite", DialIn: "+49 30 1234"},
+	}
+	uuid, err := ea.Create(evt)
+	assert.NoError(t, err)
+
+	created, err := ea.GetByUUID(uuid)
+	assert.NoError(t, err)
+	assert.Equal(t, evt.Location, created.Location)
+	assert.Equal(t, evt.URL, created.URL)
+	assert.Equal(t, evt.Conference, created.Conference)
+
+	results, err := ea.Search("alexanderplatz jitsi", time.Time{}, time.Time{}, 0)
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, uuid, results[0].UUID)
+	}
+
+	where, err := filter.Parse(`location ~ "alex"`)
+	assert.NoError(t, err)
+	events, err := ea.GetByFilter(time.Time{}, time.Time{}, where, models.DateFrom, models.Asc, 0)
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+
+	plain, err := ea.GetByUUID("123e4567-e89b-12d3-a456-426614174000")
+	assert.NoError(t, err)
+	assert.Nil(t, plain.Location)
+	assert.Nil(t, plain.Conference)
+}
+
+func TestCreateOverlap(t *testing.T) {
+	reloadTestDatabase()
+
+	evt := &models.Event{
+		Title:       "Overlapping Event",
+		Description: "Overlaps Event One",
+		DateFrom:    time.Date(2023, time.October, 1, 11, 0, 0, 0, time.UTC),
+		DateTo:      time.Date(2023, time.October, 1, 13, 0, 0, 0, time.UTC),
+	}
+
+	_, err := ea.Create(evt)
+	assert.ErrorIs(t, err, models.ErrConflict)
+
+	var serr *models.StorageError
+	assert.ErrorAs(t, err, &serr)
+	assert.Equal(t, "event_overlap", serr.Code)
+	assert.Equal(t, []models.EventRef{{UUID: "123e4567-e89b-12d3-a456-426614174000", Title: "Event One"}}, serr.Conflicts)
+}
+
 func TestGetByUUID(t *testing.T) {
 	reloadTestDatabase()
 
@@ -94,7 +416,7 @@ func TestGetByUUID(t *testing.T) {
 	t.Run("Event not Found", func(t *testing.T) {
 		uuid := "_"
 		_, err := ea.GetByUUID(uuid)
-		assert.Equal(t, err, sql.ErrNoRows)
+		assert.ErrorIs(t, err, models.ErrNotFound)
 	})
 }
 
@@ -129,6 +451,71 @@ func TestUpdate(t *testing.T) {
 	assert.True(t, evt.DateTo.Equal(evtAfter.DateTo))
 }
 
+func TestUpdateNotFound(t *testing.T) {
+	reloadTestDatabase()
+
+	evt := &models.Event{
+		Title:    "New Event Title",
+		DateFrom: time.Date(2023, time.January, 3, 0, 0, 0, 0, time.UTC),
+		DateTo:   time.Date(2023, time.January, 4, 0, 0, 0, 0, time.UTC),
+		UUID:     "_",
+	}
+	err := ea.Update(evt)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestUpdateVersion(t *testing.T) {
+	reloadTestDatabase()
+
+	uuid := "123e4567-e89b-12d3-a456-426614174004"
+	evt, err := ea.GetByUUID(uuid)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, evt.Version)
+
+	evt.Title = "Renamed"
+	err = ea.Update(evt)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, evt.Version)
+
+	stale := *evt
+	stale.Version = 1
+	err = ea.Update(&stale)
+	assert.ErrorIs(t, err, models.ErrPrecondition)
+
+	err = ea.Delete(uuid, 1)
+	assert.ErrorIs(t, err, models.ErrPrecondition)
+
+	err = ea.Delete(uuid, 2)
+	assert.NoError(t, err)
+}
+
+func TestPatch(t *testing.T) {
+	reloadTestDatabase()
+
+	uuid := "123e4567-e89b-12d3-a456-426614174004"
+	evtBefore, err := ea.GetByUUID(uuid)
+	assert.NoError(t, err)
+
+	evt := &models.Event{
+		UUID:        uuid,
+		Title:       "Patched Title",
+		Description: "Ignored Description",
+	}
+	err = ea.Patch(evt, []models.EventField{models.Title})
+	assert.NoError(t, err)
+	assert.Equal(t, "Patched Title", evt.Title)
+	assert.Equal(t, evtBefore.Description, evt.Description)
+	assert.True(t, evtBefore.DateFrom.Equal(evt.DateFrom))
+	assert.Equal(t, evtBefore.Version+1, evt.Version)
+
+	evt.Version = evtBefore.Version
+	err = ea.Patch(evt, []models.EventField{models.Title})
+	assert.ErrorIs(t, err, models.ErrPrecondition)
+
+	err = ea.Patch(evt, []models.EventField{models.CreatedAt})
+	assert.ErrorIs(t, err, models.ErrValidation)
+}
+
 func TestDelete(t *testing.T) {
 	reloadTestDatabase()
 
@@ -137,9 +524,150 @@ func TestDelete(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotZero(t, evt)
 
-	err = ea.Delete(uuid)
+	err = ea.Delete(uuid, 0)
 	assert.NoError(t, err)
 
 	_, err = ea.GetByUUID(uuid)
-	assert.Equal(t, err, sql.ErrNoRows)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestDeleteNotFound(t *testing.T) {
+	reloadTestDatabase()
+
+	err := ea.Delete("_", 0)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestGetTrash(t *testing.T) {
+	reloadTestDatabase()
+
+	err := ea.Delete("123e4567-e89b-12d3-a456-426614174004", 0)
+	assert.NoError(t, err)
+
+	events, err := ea.GetTrash()
+	assert.NoError(t, err)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, "123e4567-e89b-12d3-a456-426614174004", events[0].UUID)
+		assert.NotNil(t, events[0].DeletedAt)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	reloadTestDatabase()
+
+	uuid := "123e4567-e89b-12d3-a456-426614174005"
+	_, err := ea.GetByUUID(uuid)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+
+	evt, err := ea.Restore(uuid)
+	assert.NoError(t, err)
+	assert.Nil(t, evt.DeletedAt)
+	assert.Equal(t, 2, evt.Version)
+
+	_, err = ea.GetByUUID(uuid)
+	assert.NoError(t, err)
+
+	_, err = ea.Restore(uuid)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestRestoreOverlap(t *testing.T) {
+	reloadTestDatabase()
+
+	uuid := "123e4567-e89b-12d3-a456-426614174004"
+	err := ea.Delete(uuid, 0)
+	assert.NoError(t, err)
+
+	_, err = ea.Create(&models.Event{
+		Title:       "Replacement",
+		Description: "Takes the slot of the deleted event",
+		DateFrom:    time.Date(2023, time.October, 20, 9, 0, 0, 0, time.UTC),
+		DateTo:      time.Date(2023, time.October, 20, 11, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+
+	_, err = ea.Restore(uuid)
+	assert.ErrorIs(t, err, models.ErrConflict)
+}
+
+func TestPurge(t *testing.T) {
+	reloadTestDatabase()
+
+	n, err := ea.Purge(time.Date(2023, time.September, 1, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+
+	n, err = ea.Purge(time.Date(2023, time.October, 1, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	events, err := ea.GetTrash()
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestInTx(t *testing.T) {
+	reloadTestDatabase()
+
+	newEvent := func(day int) *models.Event {
+		return &models.Event{
+			Title:       "Transactional Event",
+			Description: "Created in a transaction",
+			DateFrom:    time.Date(2023, time.January, day, 9, 0, 0, 0, time.UTC),
+			DateTo:      time.Date(2023, time.January, day, 10, 0, 0, 0, time.UTC),
+		}
+	}
+
+	t.Run("Rollback", func(t *testing.T) {
+		var uuid string
+		err := ea.InTx(func(tx models.EventAccess) error {
+			var err error
+			uuid, err = tx.Create(newEvent(1))
+			assert.NoError(t, err)
+			return tx.Delete("_", 0)
+		})
+		assert.ErrorIs(t, err, models.ErrNotFound)
+
+		_, err = ea.GetByUUID(uuid)
+		assert.ErrorIs(t, err, models.ErrNotFound)
+	})
+
+	t.Run("Commit", func(t *testing.T) {
+		var uuid string
+		err := ea.InTx(func(tx models.EventAccess) error {
+			var err error
+			uuid, err = tx.Create(newEvent(2))
+			return err
+		})
+		assert.NoError(t, err)
+
+		_, err = ea.GetByUUID(uuid)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Savepoint", func(t *testing.T) {
+		var kept, overlapping string
+		err := ea.InTx(func(tx models.EventAccess) error {
+			err := tx.InTx(func(tx models.EventAccess) error {
+				var err error
+				kept, err = tx.Create(newEvent(3))
+				return err
+			})
+			assert.NoError(t, err)
+
+			err = tx.InTx(func(tx models.EventAccess) error {
+				overlapping, _ = tx.Create(newEvent(4))
+				_, err := tx.Create(newEvent(3))
+				return err
+			})
+			assert.ErrorIs(t, err, models.ErrConflict)
+			return nil
+		})
+		assert.NoError(t, err)
+
+		_, err = ea.GetByUUID(kept)
+		assert.NoError(t, err)
+		_, err = ea.GetByUUID(overlapping)
+		assert.ErrorIs(t, err, models.ErrNotFound)
+	})
 }
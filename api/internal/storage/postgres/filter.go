@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"api/internal/filter"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var filterColumns = map[filter.Field]string{
+	filter.ID:            "id",
+	filter.UUID:          "uuid",
+	filter.Title:         "title",
+	filter.Description:   "description",
+	filter.DateFrom:      "date_from",
+	filter.DateTo:        "date_to",
+	filter.TZFrom:        "tz_from",
+	filter.TZTo:          "tz_to",
+	filter.EventLocation: "coalesce(location->>'name', '')",
+	filter.URL:           "url",
+	filter.CreatedAt:     "created_at",
+	filter.UpdatedAt:     "updated_at",
+	filter.Version:       "version",
+	filter.EventDuration: "(date_to - date_from)",
+}
+
+var filterOps = map[filter.Op]string{
+	filter.Eq:          "=",
+	filter.Ne:          "<>",
+	filter.Lt:          "<",
+	filter.Le:          "<=",
+	filter.Gt:          ">",
+	filter.Ge:          ">=",
+	filter.Contains:    "ILIKE",
+	filter.NotContains: "NOT ILIKE",
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func filterCond(expr filter.Expr, queryArgs []any) (string, []any, error) {
+	switch e := expr.(type) {
+	case *filter.And:
+		return binaryCond("AND", e.Left, e.Right, queryArgs)
+	case *filter.Or:
+		return binaryCond("OR", e.Left, e.Right, queryArgs)
+	case *filter.Not:
+		cond, queryArgs, err := filterCond(e.X, queryArgs)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("NOT (%s)", cond), queryArgs, nil
+	case *filter.Comparison:
+		if e.Field == filter.Tag {
+			return tagCond(e, queryArgs)
+		}
+		column, ok := filterColumns[e.Field]
+		if !ok {
+			return "", nil, fmt.Errorf("filter field %s not supported", e.Field)
+		}
+		return comparisonCond(column, e.Op, e.Value, queryArgs)
+	}
+	return "", nil, fmt.Errorf("filter expression %T not supported", expr)
+}
+
+func comparisonCond(column string, op filter.Op, value any, queryArgs []any) (string, []any, error) {
+	sqlOp, ok := filterOps[op]
+	if !ok {
+		return "", nil, fmt.Errorf("filter operator %s not supported", op)
+	}
+	placeholder := fmt.Sprintf("$%d", len(queryArgs)+1)
+	switch v := value.(type) {
+	case string:
+		if op == filter.Contains || op == filter.NotContains {
+			value = "%" + likeEscaper.Replace(v) + "%"
+		}
+	case time.Duration:
+		placeholder = fmt.Sprintf("make_interval(secs => %s)", placeholder)
+		value = v.Seconds()
+	}
+	return fmt.Sprintf("%s %s %s", column, sqlOp, placeholder), append(queryArgs, value), nil
+}
+
+// tagCond matches events with a tag whose name compares as e says. The
+// negated operators match events without such a tag, so that tag != "x"
+// means the opposite of tag = "x".
+func tagCond(e *filter.Comparison, queryArgs []any) (string, []any, error) {
+	exists, op := "EXISTS", e.Op
+	switch op {
+	case filter.Ne:
+		exists, op = "NOT EXISTS", filter.Eq
+	case filter.NotContains:
+		exists, op = "NOT EXISTS", filter.Contains
+	}
+	cond, queryArgs, err := comparisonCond("t.name", op, e.Value, queryArgs)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf(
+		"%s (SELECT 1 FROM event_tags et JOIN tags t ON t.id = et.tag_id WHERE et.event_id = events.id AND %s)",
+		exists, cond,
+	), queryArgs, nil
+}
+
+func binaryCond(op string, left, right filter.Expr, queryArgs []any) (string, []any, error) {
+	l, queryArgs, err := filterCond(left, queryArgs)
+	if err != nil {
+		return "", nil, err
+	}
+	r, queryArgs, err := filterCond(right, queryArgs)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("(%s %s %s)", l, op, r), queryArgs, nil
+}
@@ -12,6 +12,9 @@ import (
 
 var (
 	ea       *eventAccess
+	ta       *tagAccess
+	aa       *attachmentAccess
+	ca       *commentAccess
 	fixtures *testfixtures.Loader
 )
 
@@ -42,6 +45,9 @@ func TestMain(m *testing.M) {
 		log.Fatalf("cannot create testfixtures: %v", err)
 	}
 	ea = NewEventAccess(db)
+	ta = NewTagAccess(db)
+	aa = NewAttachmentAccess(db)
+	ca = NewCommentAccess(db)
 	code := m.Run()
 	os.Exit(code)
 }
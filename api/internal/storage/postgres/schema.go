@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// schemaColumns lists the columns the queries of this package rely on. A
+// database created by an older init script lacks some of them.
+var schemaColumns = map[string][]string{
+	"events":      strings.Split(eventColumns, ", "),
+	"tags":        {"id", "name", "color"},
+	"event_tags":  {"event_id", "tag_id"},
+	"attachments": {"id", "uuid", "event_id", "name", "content_type", "size", "created_at"},
+	"comments":    {"id", "event_id", "author", "body", "created_at", "edited_at"},
+}
+
+// CheckSchema returns an error naming the missing tables and columns if the
+// schema of db is behind the one this package expects.
+func CheckSchema(ctx context.Context, db *sql.DB) error {
+	query := `
+SELECT table_name, column_name
+FROM information_schema.columns
+WHERE table_schema = current_schema();`
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	have := map[string]bool{}
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return err
+		}
+		have[table+"."+column] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	var missing []string
+	for table, columns := range schemaColumns {
+		for _, column := range columns {
+			if !have[table+"."+column] {
+				missing = append(missing, table+"."+column)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("schema is out of date, missing %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckSchema(t *testing.T) {
+	assert.NoError(t, CheckSchema(context.Background(), ta.db))
+}
@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"api/internal/models"
+	"database/sql"
+	"errors"
+)
+
+type tagAccess struct {
+	db *sql.DB
+}
+
+func NewTagAccess(db *sql.DB) *tagAccess {
+	return &tagAccess{
+		db: db,
+	}
+}
+
+func (ta *tagAccess) GetAll() ([]models.Tag, error) {
+	query := `SELECT id, name, color FROM tags ORDER BY name;`
+	rows, err := ta.db.Query(query)
+	if err != nil {
+		return nil, storageError(err)
+	}
+	defer rows.Close()
+	tags := []models.Tag{}
+	for rows.Next() {
+		var tag models.Tag
+		err = rows.Scan(&tag.ID, &tag.Name, &tag.Color)
+		if err != nil {
+			return nil, storageError(err)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+func (ta *tagAccess) GetByID(id int) (*models.Tag, error) {
+	query := `
+SELECT id, name, color
+FROM tags
+WHERE id = $1;`
+	var tag models.Tag
+	err := ta.db.QueryRow(query, id).Scan(&tag.ID, &tag.Name, &tag.Color)
+	if err != nil {
+		return nil, tagError(err)
+	}
+	return &tag, nil
+}
+
+func (ta *tagAccess) Create(tag *models.Tag) error {
+	query := `
+INSERT INTO tags (name, color)
+VALUES ($1, $2)
+RETURNING id;`
+	err := ta.db.QueryRow(query, tag.Name, tag.Color).Scan(&tag.ID)
+	if err != nil {
+		return tagError(err)
+	}
+	return nil
+}
+
+func (ta *tagAccess) Update(tag *models.Tag) error {
+	query := `
+UPDATE tags
+SET name = $1,
+color = $2
+WHERE id = $3
+RETURNING id;`
+	err := ta.db.QueryRow(query, tag.Name, tag.Color, tag.ID).Scan(&tag.ID)
+	if err != nil {
+		return tagError(err)
+	}
+	return nil
+}
+
+func (ta *tagAccess) Delete(id int) error {
+	query := `
+DELETE FROM tags
+WHERE id = $1
+RETURNING id;`
+	err := ta.db.QueryRow(query, id).Scan(&id)
+	if err != nil {
+		return tagError(err)
+	}
+	return nil
+}
+
+func tagError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return &models.StorageError{Kind: models.ErrNotFound, Code: "not_found", Detail: "tag does not exist", Err: err}
+	}
+	return storageError(err)
+}
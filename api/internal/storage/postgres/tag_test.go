@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"api/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTagGetAll(t *testing.T) {
+	reloadTestDatabase()
+
+	tags, err := ta.GetAll()
+	assert.NoError(t, err)
+	if assert.Len(t, tags, 3) {
+		assert.Equal(t, "Customer", tags[0].Name)
+	}
+}
+
+func TestTagCreate(t *testing.T) {
+	reloadTestDatabase()
+
+	tag := &models.Tag{Name: "Travel", Color: "#aa00aa"}
+	err := ta.Create(tag)
+	assert.NoError(t, err)
+	assert.NotZero(t, tag.ID)
+
+	err = ta.Create(&models.Tag{Name: "Travel", Color: "#aa00aa"})
+	assert.ErrorIs(t, err, models.ErrConflict)
+}
+
+func TestTagUpdate(t *testing.T) {
+	reloadTestDatabase()
+
+	err := ta.Update(&models.Tag{ID: 3, Name: "Deep Work", Color: "#2e8b57"})
+	assert.NoError(t, err)
+
+	tag, err := ta.GetByID(3)
+	assert.NoError(t, err)
+	assert.Equal(t, "Deep Work", tag.Name)
+
+	err = ta.Update(&models.Tag{ID: 99, Name: "Nothing", Color: "#000000"})
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestTagDelete(t *testing.T) {
+	reloadTestDatabase()
+
+	err := ta.Delete(1)
+	assert.NoError(t, err)
+
+	evt, err := ea.GetByUUID("123e4567-e89b-12d3-a456-426614174002")
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Tag{{ID: 2, Name: "Internal", Color: "#ff8c00"}}, evt.Tags)
+
+	err = ta.Delete(1)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
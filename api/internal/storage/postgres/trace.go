@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("api/internal/storage/postgres")
+
+// startQuery starts a span for a statement run on behalf of ea, carrying the
+// SQL but not its arguments, which may contain personal data.
+func (ea *eventAccess) startQuery(query string) (context.Context, trace.Span) {
+	query = strings.TrimSpace(query)
+	op, _, _ := strings.Cut(query, " ")
+	return tracer.Start(ea.ctx, strings.ToUpper(strings.TrimSpace(op)),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBStatement(query)),
+	)
+}
+
+func endQuery(span trace.Span, err error) {
+	if err != nil && err != sql.ErrNoRows {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// query runs query and returns its rows. The span lasts until the rows are
+// closed, so it covers reading them, and records errors scanning them.
+func (ea *eventAccess) query(query string, args ...any) (*tracedRows, error) {
+	ctx, span := ea.startQuery(query)
+	rows, err := ea.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		endQuery(span, err)
+		return nil, err
+	}
+	return &tracedRows{Rows: rows, span: span}, nil
+}
+
+// tracedRows ends the span of its query when closed.
+type tracedRows struct {
+	*sql.Rows
+	span trace.Span
+	err  error
+	done bool
+}
+
+func (tr *tracedRows) Scan(dest ...any) error {
+	err := tr.Rows.Scan(dest...)
+	if err != nil && tr.err == nil {
+		tr.err = err
+	}
+	return err
+}
+
+func (tr *tracedRows) Close() error {
+	err := tr.Rows.Close()
+	if !tr.done {
+		tr.done = true
+		if tr.err == nil {
+			tr.err = tr.Rows.Err()
+		}
+		endQuery(tr.span, tr.err)
+	}
+	return err
+}
+
+func (ea *eventAccess) queryRow(query string, args ...any) *sql.Row {
+	ctx, span := ea.startQuery(query)
+	row := ea.db.QueryRowContext(ctx, query, args...)
+	endQuery(span, row.Err())
+	return row
+}
+
+func (ea *eventAccess) exec(query string, args ...any) (sql.Result, error) {
+	ctx, span := ea.startQuery(query)
+	res, err := ea.db.ExecContext(ctx, query, args...)
+	endQuery(span, err)
+	return res, err
+}
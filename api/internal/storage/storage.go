@@ -3,15 +3,29 @@ package storage
 import (
 	"api/internal/models"
 	"api/internal/storage/postgres"
+	"context"
 	"database/sql"
 )
 
 type Storage struct {
-	Event models.EventAccess
+	Event      models.EventAccess
+	Tag        models.TagAccess
+	Attachment models.AttachmentAccess
+	Comment    models.CommentAccess
+	db         *sql.DB
 }
 
 func New(db *sql.DB) *Storage {
 	return &Storage{
-		Event: postgres.NewEventAccess(db),
+		Event:      postgres.NewEventAccess(db),
+		Tag:        postgres.NewTagAccess(db),
+		Attachment: postgres.NewAttachmentAccess(db),
+		Comment:    postgres.NewCommentAccess(db),
+		db:         db,
 	}
 }
+
+// CheckSchema returns an error if the schema of the database is out of date.
+func (s *Storage) CheckSchema(ctx context.Context) error {
+	return postgres.CheckSchema(ctx, s.db)
+}
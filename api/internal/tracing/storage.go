@@ -0,0 +1,155 @@
+package tracing
+
+import (
+	"api/internal/filter"
+	"api/internal/models"
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type eventAccess struct {
+	next models.EventAccess
+	ctx  context.Context
+}
+
+// EventAccess wraps next so that every call is a span, with the statements
+// the storage runs as its children.
+func EventAccess(next models.EventAccess) models.EventAccess {
+	return &eventAccess{next: next, ctx: context.Background()}
+}
+
+func (ea *eventAccess) WithContext(ctx context.Context) models.EventAccess {
+	return &eventAccess{next: ea.next, ctx: ctx}
+}
+
+// start starts the span of a call to method and returns next bound to it.
+func (ea *eventAccess) start(method string, attrs ...attribute.KeyValue) (models.EventAccess, trace.Span) {
+	ctx, span := tracer.Start(ea.ctx, "EventAccess."+method, trace.WithAttributes(attrs...))
+	return ea.next.WithContext(ctx), span
+}
+
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		if !errors.Is(err, models.ErrNotFound) {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}
+
+func (ea *eventAccess) GetAll() ([]models.Event, error) {
+	next, span := ea.start("GetAll")
+	evts, err := next.GetAll()
+	span.SetAttributes(attribute.Int("rows", len(evts)))
+	end(span, err)
+	return evts, err
+}
+
+func (ea *eventAccess) GetByFilter(
+	startDate, endDate time.Time,
+	where filter.Expr,
+	sortField models.EventField,
+	sortOrder models.SortOrder,
+	limit int,
+) ([]models.Event, error) {
+	next, span := ea.start("GetByFilter",
+		attribute.String("window.start", startDate.Format(time.RFC3339)),
+		attribute.String("window.end", endDate.Format(time.RFC3339)),
+		attribute.Int("limit", limit),
+	)
+	evts, err := next.GetByFilter(startDate, endDate, where, sortField, sortOrder, limit)
+	span.SetAttributes(attribute.Int("rows", len(evts)))
+	end(span, err)
+	return evts, err
+}
+
+func (ea *eventAccess) Stat(startDate, endDate time.Time, where filter.Expr) (models.ListStat, error) {
+	next, span := ea.start("Stat")
+	stat, err := next.Stat(startDate, endDate, where)
+	span.SetAttributes(attribute.Int("rows", stat.Count))
+	end(span, err)
+	return stat, err
+}
+
+func (ea *eventAccess) Search(query string, startDate, endDate time.Time, limit int) ([]models.SearchResult, error) {
+	next, span := ea.start("Search", attribute.Int("limit", limit))
+	results, err := next.Search(query, startDate, endDate, limit)
+	span.SetAttributes(attribute.Int("rows", len(results)))
+	end(span, err)
+	return results, err
+}
+
+func (ea *eventAccess) Create(evt *models.Event) (string, error) {
+	next, span := ea.start("Create")
+	uuid, err := next.Create(evt)
+	end(span, err)
+	return uuid, err
+}
+
+func (ea *eventAccess) GetByUUID(uuid string) (*models.Event, error) {
+	next, span := ea.start("GetByUUID", attribute.String("event.uuid", uuid))
+	evt, err := next.GetByUUID(uuid)
+	end(span, err)
+	return evt, err
+}
+
+func (ea *eventAccess) Update(evt *models.Event) error {
+	next, span := ea.start("Update", attribute.String("event.uuid", evt.UUID))
+	err := next.Update(evt)
+	end(span, err)
+	return err
+}
+
+func (ea *eventAccess) Patch(evt *models.Event, fields []models.EventField) error {
+	next, span := ea.start("Patch", attribute.String("event.uuid", evt.UUID), attribute.Int("fields", len(fields)))
+	err := next.Patch(evt, fields)
+	end(span, err)
+	return err
+}
+
+func (ea *eventAccess) Delete(uuid string, version int) error {
+	next, span := ea.start("Delete", attribute.String("event.uuid", uuid))
+	err := next.Delete(uuid, version)
+	end(span, err)
+	return err
+}
+
+func (ea *eventAccess) GetTrash() ([]models.Event, error) {
+	next, span := ea.start("GetTrash")
+	evts, err := next.GetTrash()
+	span.SetAttributes(attribute.Int("rows", len(evts)))
+	end(span, err)
+	return evts, err
+}
+
+func (ea *eventAccess) Restore(uuid string) (*models.Event, error) {
+	next, span := ea.start("Restore", attribute.String("event.uuid", uuid))
+	evt, err := next.Restore(uuid)
+	end(span, err)
+	return evt, err
+}
+
+func (ea *eventAccess) Purge(before time.Time) (int64, error) {
+	next, span := ea.start("Purge")
+	n, err := next.Purge(before)
+	span.SetAttributes(attribute.Int64("rows", n))
+	end(span, err)
+	return n, err
+}
+
+// InTx nests the spans of the calls made in fn under the span of the
+// transaction.
+func (ea *eventAccess) InTx(fn func(tx models.EventAccess) error) error {
+	ctx, span := tracer.Start(ea.ctx, "EventAccess.InTx")
+	err := ea.next.WithContext(ctx).InTx(func(tx models.EventAccess) error {
+		return fn(&eventAccess{next: tx, ctx: ctx})
+	})
+	end(span, err)
+	return err
+}
@@ -0,0 +1,108 @@
+// Package tracing sets up OpenTelemetry tracing of requests and storage
+// calls.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/felixge/httpsnoop"
+	"github.com/gorilla/mux"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const defaultServiceName = "calendar-api"
+
+var tracer = otel.Tracer("api/internal/tracing")
+
+// Setup installs the global tracer provider and the W3C trace context
+// propagator. tracing.exporter selects where spans go: "otlp" sends them to
+// tracing.otlp.endpoint over HTTP, "stdout" prints them and "none" or empty
+// drops them. The returned function flushes pending spans.
+func Setup(ctx context.Context, config *viper.Viper) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	switch kind := config.GetString("tracing.exporter"); kind {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case "otlp":
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(config.GetString("tracing.otlp.endpoint"))}
+		if config.GetBool("tracing.otlp.insecure") {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("tracing.exporter %q not supported", kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	serviceName := config.GetString("tracing.service_name")
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+	ratio := 1.0
+	if config.IsSet("tracing.sample_ratio") {
+		ratio = config.GetFloat64("tracing.sample_ratio")
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Middleware starts a server span for each request, continuing the trace of
+// the caller if the request carries a traceparent header. It goes outside the
+// access log so that the log lines of a request carry its trace ID; Route,
+// installed with mux.Router.Use, then names the span by route template.
+// Requests that match no route keep the name "unmatched".
+func Middleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const route = "unmatched"
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPMethod(r.Method), semconv.HTTPRoute(route), semconv.URLPath(r.URL.Path)),
+		)
+		defer span.End()
+
+		snoop := httpsnoop.CaptureMetrics(h, w, r.WithContext(ctx))
+		span.SetAttributes(semconv.HTTPStatusCode(snoop.Code))
+		if snoop.Code >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(snoop.Code))
+		}
+	})
+}
+
+// Route names the span Middleware started by the template of the matched
+// route. Labelling by template rather than path keeps span names bounded.
+func Route(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cr := mux.CurrentRoute(r); cr != nil {
+			if tmpl, err := cr.GetPathTemplate(); err == nil {
+				span := trace.SpanFromContext(r.Context())
+				span.SetName(r.Method + " " + tmpl)
+				span.SetAttributes(semconv.HTTPRoute(tmpl))
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
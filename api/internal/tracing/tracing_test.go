@@ -0,0 +1,74 @@
+package tracing
+
+import (
+	"api/internal/models"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { provider.Shutdown(context.Background()) })
+	return recorder
+}
+
+type fakeEventAccess struct {
+	models.EventAccess
+	ctx context.Context
+}
+
+func (fe *fakeEventAccess) WithContext(ctx context.Context) models.EventAccess {
+	return &fakeEventAccess{ctx: ctx}
+}
+
+func (fe *fakeEventAccess) GetByUUID(uuid string) (*models.Event, error) {
+	// Stands in for a statement span of the storage.
+	_, span := otel.Tracer("test").Start(fe.ctx, "SELECT")
+	span.End()
+	return &models.Event{UUID: uuid}, nil
+}
+
+func TestMiddleware(t *testing.T) {
+	recorder := setupRecorder(t)
+	events := EventAccess(&fakeEventAccess{})
+
+	r := mux.NewRouter()
+	r.HandleFunc("/api/events/{uuid}", func(w http.ResponseWriter, r *http.Request) {
+		events.WithContext(r.Context()).GetByUUID(mux.Vars(r)["uuid"])
+		w.WriteHeader(http.StatusOK)
+	})
+	r.Use(Route)
+	h := Middleware(r)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/events/123", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/nothing", nil))
+
+	spans := recorder.Ended()
+	if !assert.Len(t, spans, 4) {
+		return
+	}
+	assert.Equal(t, "GET unmatched", spans[3].Name())
+	query, call, server := spans[0], spans[1], spans[2]
+	assert.Equal(t, "GET /api/events/{uuid}", server.Name())
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+	assert.Equal(t, "EventAccess.GetByUUID", call.Name())
+	assert.Equal(t, server.SpanContext().SpanID(), call.Parent().SpanID())
+	assert.Equal(t, call.SpanContext().SpanID(), query.Parent().SpanID())
+}
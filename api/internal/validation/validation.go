@@ -0,0 +1,257 @@
+// Package validation checks event payloads against configurable rules before
+// they reach the storage.
+package validation
+
+import (
+	"api/internal/models"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/spf13/viper"
+)
+
+type Rules struct {
+	TitleRequired          bool
+	TitleMaxLength         int
+	DescriptionMaxLength   int
+	MinDuration            time.Duration
+	MaxDuration            time.Duration
+	EarliestDate           time.Time
+	LatestDate             time.Time
+	TagNameMaxLength       int
+	CommentAuthorMaxLength int
+	CommentBodyMaxLength   int
+}
+
+const (
+	defaultTagNameMaxLength       = 64
+	defaultCommentAuthorMaxLength = 128
+	defaultCommentBodyMaxLength   = 10000
+)
+
+// NewRules reads the rules from the validation section of config. Missing or
+// zero limits are not enforced, except for the lengths of tag names and
+// comments, which fall back to defaults when missing.
+func NewRules(config *viper.Viper) (Rules, error) {
+	rules := Rules{
+		TitleRequired:          config.GetBool("validation.title.required"),
+		TitleMaxLength:         config.GetInt("validation.title.max_length"),
+		DescriptionMaxLength:   config.GetInt("validation.description.max_length"),
+		MinDuration:            config.GetDuration("validation.duration.min"),
+		MaxDuration:            config.GetDuration("validation.duration.max"),
+		TagNameMaxLength:       defaultTagNameMaxLength,
+		CommentAuthorMaxLength: defaultCommentAuthorMaxLength,
+		CommentBodyMaxLength:   defaultCommentBodyMaxLength,
+	}
+	for key, dst := range map[string]*int{
+		"validation.tag.name.max_length":       &rules.TagNameMaxLength,
+		"validation.comment.author.max_length": &rules.CommentAuthorMaxLength,
+		"validation.comment.body.max_length":   &rules.CommentBodyMaxLength,
+	} {
+		if config.IsSet(key) {
+			*dst = config.GetInt(key)
+		}
+	}
+	for key, dst := range map[string]*time.Time{
+		"validation.date.min": &rules.EarliestDate,
+		"validation.date.max": &rules.LatestDate,
+	} {
+		if !config.IsSet(key) {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, config.GetString(key))
+		if err != nil {
+			return Rules{}, fmt.Errorf("%s: %w", key, err)
+		}
+		*dst = t
+	}
+	return rules, nil
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type Errors []FieldError
+
+func (errs Errors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Field + ": " + err.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (errs *Errors) add(field, code, format string, args ...any) {
+	*errs = append(*errs, FieldError{Field: field, Code: code, Message: fmt.Sprintf(format, args...)})
+}
+
+// Event checks evt against the rules and returns all violations at once as
+// Errors, or nil if there are none.
+func (rules Rules) Event(evt *models.Event) error {
+	var errs Errors
+
+	if rules.TitleRequired && strings.TrimSpace(evt.Title) == "" {
+		errs.add("title", "required", "must not be empty")
+	}
+	if n := utf8.RuneCountInString(evt.Title); rules.TitleMaxLength > 0 && n > rules.TitleMaxLength {
+		errs.add("title", "too_long", "must be at most %d characters, got %d", rules.TitleMaxLength, n)
+	}
+	if n := utf8.RuneCountInString(evt.Description); rules.DescriptionMaxLength > 0 && n > rules.DescriptionMaxLength {
+		errs.add("description", "too_long", "must be at most %d characters, got %d", rules.DescriptionMaxLength, n)
+	}
+
+	for _, tz := range []struct {
+		field, name string
+	}{{"tz_from", evt.TZFrom}, {"tz_to", evt.TZTo}} {
+		if tz.name == "" {
+			continue
+		}
+		if _, err := models.LoadLocation(tz.name); err != nil {
+			errs.add(tz.field, "unknown_time_zone", "%q is not a known time zone", tz.name)
+		}
+	}
+
+	fromField, toField := "date_from", "date_to"
+	if evt.AllDay {
+		// The event is expected to be normalized, so date_from and date_to
+		// are derived from these.
+		fromField, toField = "start_date", "end_date"
+		n := len(errs)
+		if evt.StartDate == nil {
+			errs.add("start_date", "required", "must be set for all-day events")
+		}
+		if evt.EndDate == nil {
+			errs.add("end_date", "required", "must be set for all-day events")
+		}
+		if evt.StartDate != nil && evt.EndDate != nil && evt.EndDate.Before(*evt.StartDate) {
+			errs.add("end_date", "before_start", "must not be before start_date")
+		}
+		if len(errs) > n {
+			return errs
+		}
+	} else if evt.StartDate != nil || evt.EndDate != nil {
+		errs.add("all_day", "required", "must be true if start_date or end_date is set")
+	}
+
+	for _, date := range []struct {
+		field string
+		t     time.Time
+	}{{fromField, evt.DateFrom}, {toField, evt.DateTo}} {
+		field, t := date.field, date.t
+		switch {
+		case t.IsZero():
+			errs.add(field, "required", "must be set")
+		case !rules.EarliestDate.IsZero() && t.Before(rules.EarliestDate):
+			errs.add(field, "out_of_range", "must not be before %s", rules.EarliestDate.Format(time.RFC3339))
+		case !rules.LatestDate.IsZero() && t.After(rules.LatestDate):
+			errs.add(field, "out_of_range", "must not be after %s", rules.LatestDate.Format(time.RFC3339))
+		}
+	}
+
+	if !evt.DateFrom.IsZero() && !evt.DateTo.IsZero() {
+		d := evt.DateTo.Sub(evt.DateFrom)
+		switch {
+		case d <= 0:
+			errs.add(toField, "before_start", "must be after %s", fromField)
+		case rules.MinDuration > 0 && d < rules.MinDuration:
+			errs.add(toField, "too_short", "event must last at least %s", rules.MinDuration)
+		case rules.MaxDuration > 0 && d > rules.MaxDuration:
+			errs.add(toField, "too_long", "event must last at most %s", rules.MaxDuration)
+		}
+	}
+
+	if loc := evt.Location; loc != nil {
+		if (loc.Latitude == nil) != (loc.Longitude == nil) {
+			errs.add("location", "incomplete", "latitude and longitude must be set together")
+		}
+		if loc.Latitude != nil && (*loc.Latitude < -90 || *loc.Latitude > 90) {
+			errs.add("location/latitude", "out_of_range", "must be between -90 and 90")
+		}
+		if loc.Longitude != nil && (*loc.Longitude < -180 || *loc.Longitude > 180) {
+			errs.add("location/longitude", "out_of_range", "must be between -180 and 180")
+		}
+		if strings.TrimSpace(loc.Name) == "" && loc.Latitude == nil {
+			errs.add("location/name", "required", "must not be empty without coordinates")
+		}
+	}
+	if evt.URL != "" && !isWebURL(evt.URL) {
+		errs.add("url", "invalid", "must be an absolute http or https URL")
+	}
+	if conf := evt.Conference; conf != nil {
+		switch {
+		case conf.JoinURL == "":
+			errs.add("conference/join_url", "required", "must be set")
+		case !isWebURL(conf.JoinURL):
+			errs.add("conference/join_url", "invalid", "must be an absolute http or https URL")
+		}
+	}
+
+	for i, tag := range evt.Tags {
+		if tag.ID <= 0 {
+			errs.add(fmt.Sprintf("tags/%d/id", i), "required", "must be the id of a tag")
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func isWebURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Tag checks that tag has a name within the length limit and a hex color.
+func (rules Rules) Tag(tag *models.Tag) error {
+	var errs Errors
+
+	if strings.TrimSpace(tag.Name) == "" {
+		errs.add("name", "required", "must not be empty")
+	}
+	if n := utf8.RuneCountInString(tag.Name); rules.TagNameMaxLength > 0 && n > rules.TagNameMaxLength {
+		errs.add("name", "too_long", "must be at most %d characters, got %d", rules.TagNameMaxLength, n)
+	}
+	if !colorPattern.MatchString(tag.Color) {
+		errs.add("color", "invalid", "must be a color like #1e90ff")
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Comment checks that comment has an author and a body, both within their
+// length limits.
+func (rules Rules) Comment(comment *models.Comment) error {
+	var errs Errors
+
+	if strings.TrimSpace(comment.Author) == "" {
+		errs.add("author", "required", "must not be empty")
+	}
+	if n := utf8.RuneCountInString(comment.Author); rules.CommentAuthorMaxLength > 0 && n > rules.CommentAuthorMaxLength {
+		errs.add("author", "too_long", "must be at most %d characters, got %d", rules.CommentAuthorMaxLength, n)
+	}
+	if strings.TrimSpace(comment.Body) == "" {
+		errs.add("body", "required", "must not be empty")
+	}
+	if n := utf8.RuneCountInString(comment.Body); rules.CommentBodyMaxLength > 0 && n > rules.CommentBodyMaxLength {
+		errs.add("body", "too_long", "must be at most %d characters, got %d", rules.CommentBodyMaxLength, n)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
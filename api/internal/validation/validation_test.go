@@ -0,0 +1,180 @@
+package validation
+
+import (
+	"api/internal/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+var rules = Rules{
+	TitleRequired:          true,
+	TitleMaxLength:         10,
+	DescriptionMaxLength:   20,
+	MinDuration:            time.Minute,
+	MaxDuration:            24 * time.Hour,
+	EarliestDate:           time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC),
+	LatestDate:             time.Date(2100, time.January, 1, 0, 0, 0, 0, time.UTC),
+	TagNameMaxLength:       64,
+	CommentAuthorMaxLength: 16,
+	CommentBodyMaxLength:   100,
+}
+
+func codes(err error) []string {
+	var res []string
+	for _, ferr := range err.(Errors) {
+		res = append(res, ferr.Field+":"+ferr.Code)
+	}
+	return res
+}
+
+func TestNewRules(t *testing.T) {
+	config := viper.New()
+	config.Set("validation.title.required", true)
+	config.Set("validation.duration.max", "2h")
+	config.Set("validation.date.min", "2000-01-01T00:00:00Z")
+
+	r, err := NewRules(config)
+	assert.NoError(t, err)
+	assert.Equal(t, Rules{
+		TitleRequired:          true,
+		MaxDuration:            2 * time.Hour,
+		EarliestDate:           time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC),
+		TagNameMaxLength:       64,
+		CommentAuthorMaxLength: 128,
+		CommentBodyMaxLength:   10000,
+	}, r)
+
+	config.Set("validation.tag.name.max_length", 32)
+	config.Set("validation.comment.body.max_length", 500)
+	r, err = NewRules(config)
+	assert.NoError(t, err)
+	assert.Equal(t, 32, r.TagNameMaxLength)
+	assert.Equal(t, 500, r.CommentBodyMaxLength)
+
+	config.Set("validation.date.max", "tomorrow")
+	_, err = NewRules(config)
+	assert.Error(t, err)
+}
+
+func TestEvent(t *testing.T) {
+	from := time.Date(2023, time.October, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("Valid", func(t *testing.T) {
+		err := rules.Event(&models.Event{Title: "Standup", DateFrom: from, DateTo: from.Add(15 * time.Minute)})
+		assert.NoError(t, err)
+	})
+
+	t.Run("All Errors At Once", func(t *testing.T) {
+		err := rules.Event(&models.Event{
+			Title:       " ",
+			Description: strings.Repeat("x", 21),
+			DateTo:      from,
+		})
+		assert.Equal(t, []string{"title:required", "description:too_long", "date_from:required"}, codes(err))
+	})
+
+	t.Run("Too Long Title", func(t *testing.T) {
+		err := rules.Event(&models.Event{Title: strings.Repeat("ü", 11), DateFrom: from, DateTo: from.Add(time.Hour)})
+		assert.Equal(t, []string{"title:too_long"}, codes(err))
+	})
+
+	t.Run("End Before Start", func(t *testing.T) {
+		err := rules.Event(&models.Event{Title: "Standup", DateFrom: from, DateTo: from.Add(-time.Hour)})
+		assert.Equal(t, []string{"date_to:before_start"}, codes(err))
+	})
+
+	t.Run("Duration Limits", func(t *testing.T) {
+		err := rules.Event(&models.Event{Title: "Standup", DateFrom: from, DateTo: from.Add(time.Second)})
+		assert.Equal(t, []string{"date_to:too_short"}, codes(err))
+		err = rules.Event(&models.Event{Title: "Standup", DateFrom: from, DateTo: from.Add(25 * time.Hour)})
+		assert.Equal(t, []string{"date_to:too_long"}, codes(err))
+	})
+
+	t.Run("Date Range", func(t *testing.T) {
+		early := time.Date(1999, time.December, 31, 0, 0, 0, 0, time.UTC)
+		err := rules.Event(&models.Event{Title: "Party", DateFrom: early, DateTo: early.Add(time.Hour)})
+		assert.Equal(t, []string{"date_from:out_of_range", "date_to:out_of_range"}, codes(err))
+	})
+
+	t.Run("All Day", func(t *testing.T) {
+		start := models.Date{Year: 2023, Month: time.December, Day: 24}
+		end := start.AddDays(1)
+		evt := &models.Event{Title: "Holidays", AllDay: true, StartDate: &start, EndDate: &end}
+		evt.Normalize()
+		err := rules.Event(evt)
+		assert.Equal(t, []string{"end_date:too_long"}, codes(err))
+
+		evt = &models.Event{Title: "Holidays", AllDay: true, StartDate: &end, EndDate: &start}
+		evt.Normalize()
+		err = rules.Event(evt)
+		assert.Equal(t, []string{"end_date:before_start"}, codes(err))
+
+		err = rules.Event(&models.Event{Title: "Holidays", AllDay: true, StartDate: &start})
+		assert.Equal(t, []string{"end_date:required"}, codes(err))
+
+		err = rules.Event(&models.Event{Title: "Holidays", StartDate: &start, DateFrom: from, DateTo: from.Add(time.Hour)})
+		assert.Equal(t, []string{"all_day:required"}, codes(err))
+	})
+
+	t.Run("Time Zones", func(t *testing.T) {
+		err := rules.Event(&models.Event{Title: "Standup", DateFrom: from, DateTo: from.Add(time.Hour), TZFrom: "Europe/Berlin"})
+		assert.NoError(t, err)
+		err = rules.Event(&models.Event{Title: "Standup", DateFrom: from, DateTo: from.Add(time.Hour), TZFrom: "UTC", TZTo: "Mars/Olympus_Mons"})
+		assert.Equal(t, []string{"tz_to:unknown_time_zone"}, codes(err))
+	})
+
+	t.Run("Tags", func(t *testing.T) {
+		err := rules.Event(&models.Event{Title: "Standup", DateFrom: from, DateTo: from.Add(time.Hour), Tags: []models.Tag{{ID: 1}, {Name: "focus"}}})
+		assert.Equal(t, []string{"tags/1/id:required"}, codes(err))
+	})
+
+	t.Run("Location And Links", func(t *testing.T) {
+		lat, lng := 52.52, 13.405
+		valid := models.Event{
+			Title:      "Standup",
+			DateFrom:   from,
+			DateTo:     from.Add(time.Hour),
+			Location:   &models.Location{Name: "Berlin Office", Latitude: &lat, Longitude: &lng},
+			URL:        "https://example.com/agenda",
+			Conference: &models.Conference{Provider: "Jitsi", JoinURL: "https://meet.jit.si/standup"},
+		}
+		assert.NoError(t, rules.Event(&valid))
+
+		badLat := 91.0
+		evt := valid
+		evt.Location = &models.Location{Latitude: &badLat}
+		evt.URL = "example.com"
+		evt.Conference = &models.Conference{Provider: "Phone"}
+		err := rules.Event(&evt)
+		assert.Equal(t, []string{"location:incomplete", "location/latitude:out_of_range", "url:invalid", "conference/join_url:required"}, codes(err))
+	})
+
+	t.Run("No Limits", func(t *testing.T) {
+		err := Rules{}.Event(&models.Event{Description: strings.Repeat("x", 1<<20), DateFrom: from, DateTo: from.Add(time.Second)})
+		assert.NoError(t, err)
+	})
+}
+
+func TestTag(t *testing.T) {
+	assert.NoError(t, rules.Tag(&models.Tag{Name: "Customer", Color: "#1E90ff"}))
+
+	err := rules.Tag(&models.Tag{Name: " ", Color: "red"})
+	assert.Equal(t, []string{"name:required", "color:invalid"}, codes(err))
+
+	err = rules.Tag(&models.Tag{Name: strings.Repeat("x", 65), Color: "#000000"})
+	assert.Equal(t, []string{"name:too_long"}, codes(err))
+}
+
+func TestComment(t *testing.T) {
+	assert.NoError(t, rules.Comment(&models.Comment{Author: "alice", Body: "Can we move this to 3pm?"}))
+
+	err := rules.Comment(&models.Comment{Body: "\n"})
+	assert.Equal(t, []string{"author:required", "body:required"}, codes(err))
+
+	err = rules.Comment(&models.Comment{Author: strings.Repeat("x", 17), Body: strings.Repeat("x", 101)})
+	assert.Equal(t, []string{"author:too_long", "body:too_long"}, codes(err))
+}
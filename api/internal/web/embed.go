@@ -0,0 +1,21 @@
+//go:build embed
+
+package web
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// dist holds a copy of client/dist, see the README for how to build with it.
+//
+//go:embed all:dist
+var dist embed.FS
+
+func embedded() (fs.FS, bool) {
+	fsys, err := fs.Sub(dist, "dist")
+	if err != nil {
+		panic(err)
+	}
+	return fsys, true
+}
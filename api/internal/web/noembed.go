@@ -0,0 +1,9 @@
+//go:build !embed
+
+package web
+
+import "io/fs"
+
+func embedded() (fs.FS, bool) {
+	return nil, false
+}
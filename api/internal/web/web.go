@@ -0,0 +1,168 @@
+// Package web serves the built client, from disk or embedded in the binary,
+// as a single-page application.
+package web
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const indexFile = "index.html"
+
+// hashedName matches the names webpack gives assets with a content hash, like
+// bundle.3f2a9c81d0e4b7a5.js. They never change and can be cached forever.
+var hashedName = regexp.MustCompile(`\.[0-9a-f]{8,}\.[a-z0-9]+$`)
+
+// encodings lists the precompressed variants looked for next to each file, in
+// order of preference.
+var encodings = []struct {
+	name, ext string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// FS returns the client build embedded in the binary if frontend.embedded is
+// set, and the directory frontend.path otherwise.
+func FS(config *viper.Viper) (fs.FS, error) {
+	if config.GetBool("frontend.embedded") {
+		fsys, ok := embedded()
+		if !ok {
+			return nil, errors.New("frontend.embedded is set but the binary was built without the embed tag")
+		}
+		return fsys, nil
+	}
+	return os.DirFS(config.GetString("frontend.path")), nil
+}
+
+type handler struct {
+	fsys  fs.FS
+	files sync.Map
+}
+
+// file is the contents of a file read from fsys with its ETag, and the
+// modification time and size it had when read.
+type file struct {
+	b       []byte
+	etag    string
+	modTime time.Time
+	size    int64
+}
+
+// Handler serves the files in fsys. Paths without a file extension that do
+// not name a file are routes of the client and get index.html, so deep links
+// work; missing assets and unknown API paths get a 404. The router adds Vary:
+// Accept-Encoding for the precompressed variants.
+func Handler(fsys fs.FS) http.Handler {
+	return &handler{fsys: fsys}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	if name == "" {
+		name = indexFile
+	}
+	if !h.isFile(name) {
+		if path.Ext(name) != "" || name == "api" || strings.HasPrefix(name, "api/") {
+			http.NotFound(w, r)
+			return
+		}
+		name = indexFile
+	}
+	h.serveFile(w, r, name)
+}
+
+func (h *handler) isFile(name string) bool {
+	info, err := fs.Stat(h.fsys, name)
+	return err == nil && info.Mode().IsRegular()
+}
+
+func (h *handler) serveFile(w http.ResponseWriter, r *http.Request, name string) {
+	header := w.Header()
+	if hashedName.MatchString(name) {
+		header.Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		// Revalidate with the ETag so a new deployment is picked up at once.
+		header.Set("Cache-Control", "no-cache")
+	}
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	header.Set("Content-Type", contentType)
+
+	served := name
+	accept := r.Header.Get("Accept-Encoding")
+	for _, enc := range encodings {
+		if acceptsEncoding(accept, enc.name) && h.isFile(name+enc.ext) {
+			served = name + enc.ext
+			header.Set("Content-Encoding", enc.name)
+			break
+		}
+	}
+
+	f, err := h.file(served)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	header.Set("ETag", f.etag)
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(f.b))
+}
+
+// file returns the contents of name with a strong ETag. They are read again
+// only if the modification time or size of the file changed, as when the
+// client is rebuilt into frontend.path while the server runs; embedded files
+// never change.
+func (h *handler) file(name string) (*file, error) {
+	info, err := fs.Stat(h.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	if cached, ok := h.files.Load(name); ok {
+		f := cached.(*file)
+		if f.modTime.Equal(info.ModTime()) && f.size == info.Size() {
+			return f, nil
+		}
+	}
+	b, err := fs.ReadFile(h.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(b)
+	f := &file{b: b, etag: `"` + hex.EncodeToString(sum[:12]) + `"`, modTime: info.ModTime(), size: info.Size()}
+	h.files.Store(name, f)
+	return f, nil
+}
+
+// acceptsEncoding reports whether the Accept-Encoding header accept allows
+// enc, i.e. lists it without q=0.
+func acceptsEncoding(accept, enc string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), enc) {
+			continue
+		}
+		q := strings.ReplaceAll(params, " ", "")
+		return q != "q=0" && q != "q=0.0" && q != "q=0.00" && q != "q=0.000"
+	}
+	return false
+}
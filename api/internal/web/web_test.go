@@ -0,0 +1,86 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html":                    {Data: []byte("<html>index</html>")},
+		"bundle.3f2a9c81d0e4b7a5.js":    {Data: []byte("console.log('plain')")},
+		"bundle.3f2a9c81d0e4b7a5.js.gz": {Data: []byte("gzipped")},
+		"bundle.3f2a9c81d0e4b7a5.js.br": {Data: []byte("brotli")},
+		"favicon.ico":                   {Data: []byte("icon")},
+	}
+	h := Handler(fsys)
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		encoding     string
+		status       int
+		body         string
+		cacheControl string
+		contentEnc   string
+	}{
+		{"Root", http.MethodGet, "/", "", http.StatusOK, "<html>index</html>", "no-cache", ""},
+		{"Client Route", http.MethodGet, "/week/2024/12", "", http.StatusOK, "<html>index</html>", "no-cache", ""},
+		{"Hashed Asset", http.MethodGet, "/bundle.3f2a9c81d0e4b7a5.js", "", http.StatusOK, "console.log('plain')", "public, max-age=31536000, immutable", ""},
+		{"Brotli Preferred", http.MethodGet, "/bundle.3f2a9c81d0e4b7a5.js", "gzip, br", http.StatusOK, "brotli", "public, max-age=31536000, immutable", "br"},
+		{"Gzip", http.MethodGet, "/bundle.3f2a9c81d0e4b7a5.js", "gzip, br;q=0", http.StatusOK, "gzipped", "public, max-age=31536000, immutable", "gzip"},
+		{"Unhashed Asset", http.MethodGet, "/favicon.ico", "", http.StatusOK, "icon", "no-cache", ""},
+		{"Missing Asset", http.MethodGet, "/bundle.0000000000.js", "", http.StatusNotFound, "", "", ""},
+		{"Unknown API Path", http.MethodGet, "/api/nope", "", http.StatusNotFound, "", "", ""},
+		{"Traversal", http.MethodGet, "/../../etc/passwd", "", http.StatusOK, "<html>index</html>", "no-cache", ""},
+		{"Post", http.MethodPost, "/", "", http.StatusMethodNotAllowed, "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.encoding != "" {
+				req.Header.Set("Accept-Encoding", tt.encoding)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status != http.StatusOK {
+				return
+			}
+			assert.Equal(t, tt.body, w.Body.String())
+			assert.Equal(t, tt.cacheControl, w.Header().Get("Cache-Control"))
+			assert.Equal(t, tt.contentEnc, w.Header().Get("Content-Encoding"))
+		})
+	}
+
+	t.Run("Not Modified", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		etag := w.Header().Get("ETag")
+		assert.NotEmpty(t, etag)
+
+		req := httptest.NewRequest(http.MethodGet, "/week/2024/12", nil)
+		req.Header.Set("If-None-Match", etag)
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotModified, w.Code)
+	})
+
+	t.Run("Rebuilt", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		etag := w.Header().Get("ETag")
+
+		fsys["index.html"] = &fstest.MapFile{Data: []byte("<html>rebuilt</html>"), ModTime: time.Now()}
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, "<html>rebuilt</html>", w.Body.String())
+		assert.NotEqual(t, etag, w.Header().Get("ETag"))
+	})
+}
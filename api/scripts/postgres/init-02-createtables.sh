@@ -5,38 +5,104 @@ set -e
 db_name="calendar"
 db_name_test="${db_name}_test"
 
-psql -v ON_ERROR_STOP=1 --username "$POSTGRES_USER" --dbname "$db_name" <<-EOSQL
+create_tables() {
+psql -v ON_ERROR_STOP=1 --username "$POSTGRES_USER" --dbname "$1" <<-EOSQL
     CREATE EXTENSION IF NOT EXISTS btree_gist;
-    
+
     CREATE TABLE events (
       id          SERIAL PRIMARY KEY,
       uuid        VARCHAR(64) NOT NULL UNIQUE,
       title	      TEXT NOT NULL,
       description TEXT NOT NULL,
-      date_from   TIMESTAMP NOT NULL,
-      date_to     TIMESTAMP NOT NULL,
-      created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+      date_from   TIMESTAMPTZ NOT NULL,
+      date_to     TIMESTAMPTZ NOT NULL,
+      tz_from     TEXT NOT NULL DEFAULT '',
+      tz_to       TEXT NOT NULL DEFAULT '',
+      all_day     BOOLEAN NOT NULL DEFAULT false,
+      start_date  DATE,
+      end_date    DATE,
+      location    JSONB,
+      url         TEXT NOT NULL DEFAULT '',
+      conference  JSONB,
+      created_at  TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+      updated_at  TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+      version     INTEGER NOT NULL DEFAULT 1,
+      deleted_at  TIMESTAMPTZ,
+      search      TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', title), 'A') ||
+        setweight(to_tsvector('english', description), 'B') ||
+        setweight(to_tsvector('english',
+          coalesce(location->>'name', '') || ' ' || coalesce(conference->>'provider', '')
+        ), 'C')
+      ) STORED,
+      CONSTRAINT event_all_day_dates CHECK (
+        all_day = (start_date IS NOT NULL AND end_date IS NOT NULL) AND
+        (start_date IS NULL OR end_date IS NULL OR end_date >= start_date)
+      )
     );
 
     ALTER TABLE events ADD CONSTRAINT event_overlap EXCLUDE USING gist (
-        tsrange(date_from, date_to, '[)') WITH &&
+        tstzrange(date_from, date_to, '[)') WITH &&
+    ) WHERE (NOT all_day AND deleted_at IS NULL);
+
+    -- Listeners LISTEN on event_changes for the UUIDs of changed events. Writes
+    -- of comments and attachments update their event, so they are announced
+    -- the same way.
+    CREATE FUNCTION notify_event_change() RETURNS trigger AS \$\$
+    BEGIN
+      IF TG_OP = 'DELETE' THEN
+        PERFORM pg_notify('event_changes', OLD.uuid);
+      ELSE
+        PERFORM pg_notify('event_changes', NEW.uuid);
+      END IF;
+      RETURN NULL;
+    END;
+    \$\$ LANGUAGE plpgsql;
+
+    CREATE TRIGGER events_notify AFTER INSERT OR UPDATE OR DELETE ON events
+      FOR EACH ROW EXECUTE FUNCTION notify_event_change();
+
+    CREATE INDEX events_search_idx ON events USING gin (search);
+    CREATE INDEX events_deleted_at_idx ON events (deleted_at) WHERE deleted_at IS NOT NULL;
+
+    CREATE TABLE tags (
+      id    SERIAL PRIMARY KEY,
+      name  TEXT NOT NULL UNIQUE,
+      color VARCHAR(7) NOT NULL CHECK (color ~ '^#[0-9a-fA-F]{6}$')
     );
-EOSQL
 
-psql -v ON_ERROR_STOP=1 --username "$POSTGRES_USER" --dbname "$db_name_test" <<-EOSQL
-    CREATE EXTENSION IF NOT EXISTS btree_gist;
+    CREATE TABLE event_tags (
+      event_id INTEGER NOT NULL REFERENCES events (id) ON DELETE CASCADE,
+      tag_id   INTEGER NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+      PRIMARY KEY (event_id, tag_id)
+    );
 
-    CREATE TABLE events (
-      id          SERIAL PRIMARY KEY,
-      uuid        VARCHAR(64) NOT NULL UNIQUE,
-      title	      TEXT NOT NULL,
-      description TEXT NOT NULL,
-      date_from   TIMESTAMP NOT NULL,
-      date_to     TIMESTAMP NOT NULL,
-      created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    CREATE INDEX event_tags_tag_id_idx ON event_tags (tag_id);
+
+    CREATE TABLE attachments (
+      id           SERIAL PRIMARY KEY,
+      uuid         VARCHAR(64) NOT NULL UNIQUE,
+      event_id     INTEGER NOT NULL REFERENCES events (id) ON DELETE CASCADE,
+      name         TEXT NOT NULL,
+      content_type TEXT NOT NULL,
+      size         BIGINT NOT NULL,
+      created_at   TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
     );
 
-    ALTER TABLE events ADD CONSTRAINT event_overlap EXCLUDE USING gist (
-        tsrange(date_from, date_to, '[)') WITH &&
+    CREATE INDEX attachments_event_id_idx ON attachments (event_id);
+
+    CREATE TABLE comments (
+      id         SERIAL PRIMARY KEY,
+      event_id   INTEGER NOT NULL REFERENCES events (id) ON DELETE CASCADE,
+      author     TEXT NOT NULL,
+      body       TEXT NOT NULL,
+      created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+      edited_at  TIMESTAMPTZ
     );
+
+    CREATE INDEX comments_event_id_created_at_idx ON comments (event_id, created_at, id);
 EOSQL
+}
+
+create_tables "$db_name"
+create_tables "$db_name_test"
@@ -8,6 +8,7 @@ import Main from "../layout/Main";
 import EventModal from "../EventModal";
 import Toast from "../Toast";
 import { queryClient } from "../../react-query";
+import { useCalendarSettings } from "../../hooks/events";
 import { useStorePick } from "../../store";
 
 import "./Styles.css";
@@ -22,6 +23,11 @@ function Wrapper({ children }: { children: React.ReactNode }) {
 
 function App() {
     const { isModalOpen } = useStorePick("isModalOpen");
+    // Week numbers are only right once the server's week start is known.
+    const { isLoading } = useCalendarSettings();
+    if (isLoading) {
+        return null;
+    }
     return (
         <div className="app">
             <Toast />
@@ -1,7 +1,12 @@
 import React, { useState } from "react";
 
 import { useEvents } from "../../context/events";
-import { filterEvents, viewDateFilter } from "../../services/events";
+import {
+    filterEvents,
+    viewDateFilter,
+    tagFilter,
+} from "../../services/events";
+import { useStorePick } from "../../store";
 import { CalEvent } from "../../types";
 import EventPopover from "../EventPopover";
 
@@ -13,7 +18,12 @@ export interface EventDots {
 
 export default function EventDots({ viewDate }: EventDots) {
     let { events } = useEvents();
-    events = filterEvents(events, viewDateFilter(viewDate));
+    const { tagNames } = useStorePick("tagNames");
+    events = filterEvents(
+        events,
+        viewDateFilter(viewDate),
+        tagFilter(tagNames)
+    );
 
     return (
         <div className="event-dots">
@@ -24,7 +24,7 @@ export default function EventPopover({
 
     function onDeleteEvent(e: React.MouseEvent) {
         deleteMutation.mutate(
-            { uuid: event.uuid },
+            { uuid: event.uuid, eventInterval: pick(["start", "end"], event) },
             {
                 onSettled: () => {
                     setIsPopoverActive(false);
@@ -8,7 +8,11 @@ import { useEvents } from "../../context/events";
 import { useStorePick } from "../../store";
 import { WindowHelper } from "../../services/windowHelper";
 import { clampToDayInterval } from "../../services/dates";
-import { filterEvents, viewDateFilter } from "../../services/events";
+import {
+    filterEvents,
+    viewDateFilter,
+    tagFilter,
+} from "../../services/events";
 import { CalEvent } from "../../types";
 
 import "./Styles.css";
@@ -20,9 +24,14 @@ export interface EventsProps {
 
 export default function Events({ viewDate, windowHelper }: EventsProps) {
     let { events } = useEvents();
-    const { eventFilter } = useStorePick("eventFilter");
+    const { eventFilter, tagNames } = useStorePick("eventFilter", "tagNames");
 
-    events = filterEvents(events, viewDateFilter(viewDate), where(eventFilter));
+    events = filterEvents(
+        events,
+        viewDateFilter(viewDate),
+        where(eventFilter),
+        tagFilter(tagNames)
+    );
 
     return (
         <Fragment>
@@ -0,0 +1,37 @@
+import React from "react";
+
+import { useTags } from "../../hooks/events";
+import { useStorePick } from "../../store";
+
+import "./Styles.css";
+
+export default function TagFilter() {
+    const { data: tags = [] } = useTags();
+    const { tagNames, toggleTagName } = useStorePick(
+        "tagNames",
+        "toggleTagName"
+    );
+
+    if (tags.length === 0) {
+        return null;
+    }
+    return (
+        <div className="tag-filter">
+            <div className="tag-filter__title">Tags</div>
+            {tags.map((tag) => (
+                <label key={tag.id} className="tag-filter__tag">
+                    <input
+                        type="checkbox"
+                        checked={tagNames.includes(tag.name)}
+                        onChange={() => toggleTagName(tag.name)}
+                    />
+                    <span
+                        className="tag-filter__color"
+                        style={{ background: tag.color }}
+                    />
+                    {tag.name}
+                </label>
+            ))}
+        </div>
+    );
+}
@@ -20,6 +20,18 @@ export default function Toast() {
                 >
                     <div className="toast-title">{toast.title}</div>
                     <div className="toast-description">{toast.description}</div>
+                    {toast.action && (
+                        <button
+                            className="toast-action"
+                            onClick={(e) => {
+                                e.stopPropagation();
+                                toast.action?.onClick();
+                                removeToast(toast.id);
+                            }}
+                        >
+                            {toast.action.label}
+                        </button>
+                    )}
                 </div>
             ))}
         </div>
@@ -17,8 +17,7 @@ import { WindowHelper } from "../../services/windowHelper";
 import { useStorePick } from "../../store";
 import {
     getDay,
-    getWeek,
-    getYear,
+    getWeekSpec,
     setHours,
     weekdayMap,
     formatDate,
@@ -45,10 +44,8 @@ function WeekViewHeader() {
         "updateViewDate"
     );
 
-    const dateStr = `Week ${getWeek(viewDate, {
-        weekStartsOn: 1,
-        firstWeekContainsDate: 7,
-    })}, ${getYear(viewDate)}`;
+    const [year, week] = getWeekSpec(viewDate);
+    const dateStr = `Week ${week}, ${year}`;
 
     function onClickLeftChv() {
         updateViewDate(decWeek);
@@ -1,6 +1,7 @@
 import React from "react";
 
 import NewEvent from "../../NewEvent";
+import TagFilter from "../../TagFilter";
 
 import "./Styles.css";
 
@@ -8,6 +9,7 @@ export default function Sidebar() {
     return (
         <div className="sidebar">
             <NewEvent />
+            <TagFilter />
         </div>
     );
 }
@@ -33,12 +33,14 @@ type SortOrder = "asc" | "desc";
 
 export const api = {
     ROUTES: {
+        GET_CALENDAR: `${REST_API}/calendar`,
         GET_ALL: `${REST_API}/events`,
         GET_BY_MONTH: (year: number, month: number, tz: string = timezone) =>
             `${REST_API}/events/month?` +
             buildQueryString({
                 year: year.toString(),
-                month: month.toString(),
+                // date-fns months are zero-based, the API's are not.
+                month: (month + 1).toString(),
                 tz,
             }),
         GET_BY_WEEK: (year: number, week: number, tz: string = timezone) =>
@@ -71,5 +73,7 @@ export const api = {
         CREATE: `${REST_API}/events`,
         UPDATE: (uuid: string) => `${REST_API}/events/${uuid}`,
         DELETE: (uuid: string) => `${REST_API}/events/${uuid}`,
+        RESTORE: (uuid: string) => `${REST_API}/events/${uuid}/restore`,
+        GET_TAGS: `${REST_API}/tags`,
     },
 };
@@ -2,8 +2,10 @@ import { useQuery, useMutation, useQueryClient } from "@tanstack/react-query";
 import { pick, uniqBy, prop } from "ramda";
 
 import {
+    addDays,
     format,
     formatRFC3339,
+    parseISO,
     parseJSON,
     eachDayInWeek,
     eachDayInMonth,
@@ -14,6 +16,8 @@ import {
     getMonthInterval,
     getWeekSpec,
     getMonthSpec,
+    getWeekOptions,
+    setWeekStart,
 } from "../services/dates";
 import {
     findClosestPreviousEvent,
@@ -21,9 +25,10 @@ import {
 } from "../services/events";
 import { api } from "../constants";
 import { queryClient, queryKeys } from "../react-query";
+import { invalidateOnEventChange } from "../react-query/invalidate";
 import { filterEvents } from "../services/events";
 import { useStorePick } from "../store";
-import { CalEvent, CalInterval, isArrayOfCalEvents } from "../types";
+import { CalEvent, CalInterval, CalTag, isArrayOfCalEvents } from "../types";
 
 const adaptor = {
     event(remote: {
@@ -33,18 +38,39 @@ const adaptor = {
         description: string;
         date_from: string;
         date_to: string;
+        all_day?: boolean;
+        start_date?: string;
+        end_date?: string;
+        tags?: CalTag[];
         created_at: string;
     }): CalEvent {
+        // All-day events cover the same dates in every timezone, so they
+        // start and end at local midnight.
+        if (remote.all_day && remote.start_date && remote.end_date) {
+            return {
+                uuid: remote.uuid,
+                title: remote.title,
+                description: remote.description,
+                start: parseISO(remote.start_date),
+                end: addDays(parseISO(remote.end_date), 1),
+                tags: remote.tags,
+            };
+        }
         return {
             uuid: remote.uuid,
             title: remote.title,
             description: remote.description,
             start: parseJSON(remote.date_from),
             end: parseJSON(remote.date_to),
+            tags: remote.tags,
         };
     },
 };
 
+function localTimeZone() {
+    return Intl.DateTimeFormat().resolvedOptions().timeZone;
+}
+
 const viewDateToArgs = {
     day(viewDate: Date): [string] {
         return [format(viewDate, "yyyy-MM-dd")];
@@ -53,6 +79,42 @@ const viewDateToArgs = {
     month: getMonthSpec,
 };
 
+/**
+ * Loads the calendar settings of the server and numbers weeks accordingly.
+ */
+export function useCalendarSettings() {
+    const queryFn = async () => {
+        const res = await fetch(api.ROUTES.GET_CALENDAR);
+        const json = await res.json();
+        if (res.status !== 200) {
+            throw Error(
+                json?.detail ||
+                    json?.message ||
+                    `Non-200 status code: ${res.status}`
+            );
+        }
+        setWeekStart(json.week_start);
+        return json;
+    };
+    return useQuery(queryKeys.calendar(), queryFn, { staleTime: Infinity });
+}
+
+export function useTags() {
+    const queryFn = async (): Promise<CalTag[]> => {
+        const res = await fetch(api.ROUTES.GET_TAGS);
+        const json = await res.json();
+        if (res.status !== 200) {
+            throw Error(
+                json?.detail ||
+                    json?.message ||
+                    `Non-200 status code: ${res.status}`
+            );
+        }
+        return json || [];
+    };
+    return useQuery(queryKeys.tags(), queryFn);
+}
+
 export function useEventsForDay(viewDate: Date) {
     const [isoDate] = viewDateToArgs.day(viewDate);
     const queryClient = useQueryClient();
@@ -60,7 +122,11 @@ export function useEventsForDay(viewDate: Date) {
         const res = await fetch(api.ROUTES.GET_BY_DAY(isoDate));
         const json = await res.json();
         if (res.status !== 200) {
-            throw Error(json?.message || `Non-200 status code: ${res.status}`);
+            throw Error(
+                json?.detail ||
+                    json?.message ||
+                    `Non-200 status code: ${res.status}`
+            );
         }
         return json?.map(adaptor.event) || [];
     };
@@ -110,7 +176,11 @@ export function useEventsForWeek(viewDate: Date) {
         const res = await fetch(api.ROUTES.GET_BY_WEEK(year, week));
         const json = await res.json();
         if (res.status !== 200) {
-            throw Error(json?.message || `Non-200 status code: ${res.status}`);
+            throw Error(
+                json?.detail ||
+                    json?.message ||
+                    `Non-200 status code: ${res.status}`
+            );
         }
         return json?.map(adaptor.event) || [];
     };
@@ -154,7 +224,11 @@ export function useEventsForMonth(viewDate: Date) {
         const res = await fetch(api.ROUTES.GET_BY_MONTH(year, month));
         const json = await res.json();
         if (res.status !== 200) {
-            throw Error(json?.message || `Non-200 status code: ${res.status}`);
+            throw Error(
+                json?.detail ||
+                    json?.message ||
+                    `Non-200 status code: ${res.status}`
+            );
         }
         return json?.map(adaptor.event) || [];
     };
@@ -164,9 +238,7 @@ export function useEventsForMonth(viewDate: Date) {
             {
                 const eachWeek = eachWeekOfInterval(
                     getMonthInterval(viewDate),
-                    {
-                        weekStartsOn: 1,
-                    }
+                    getWeekOptions()
                 );
                 let events = eachWeek.flatMap((w) => {
                     const [year, week] = viewDateToArgs.week(w);
@@ -217,7 +289,11 @@ export async function getPreviousEvents(interval: CalInterval) {
     );
     const json = await res.json();
     if (res.status !== 200) {
-        throw Error(json?.message || `Non-200 status code: ${res.status}`);
+        throw Error(
+            json?.detail ||
+                json?.message ||
+                `Non-200 status code: ${res.status}`
+        );
     }
     return json?.map(adaptor.event) || [];
 }
@@ -248,7 +324,11 @@ export async function getNextEvents(interval: CalInterval) {
     );
     const json = await res.json();
     if (res.status !== 200) {
-        throw Error(json?.message || `Non-200 status code: ${res.status}`);
+        throw Error(
+            json?.detail ||
+                json?.message ||
+                `Non-200 status code: ${res.status}`
+        );
     }
     return json?.map(adaptor.event) || [];
 }
@@ -285,11 +365,16 @@ export function useAddEvent() {
                 description: description,
                 date_from: formatRFC3339(eventInterval.start),
                 date_to: formatRFC3339(eventInterval.end),
+                tz_from: localTimeZone(),
             }),
         });
         const json = await res.json();
         if (res.status !== 200) {
-            throw Error(json?.message || `Non-200 status code: ${res.status}`);
+            throw Error(
+                json?.detail ||
+                    json?.message ||
+                    `Non-200 status code: ${res.status}`
+            );
         }
         return json;
     };
@@ -337,11 +422,16 @@ export function useEditEvent() {
                 description: description,
                 date_from: formatRFC3339(eventInterval.start),
                 date_to: formatRFC3339(eventInterval.end),
+                tz_from: localTimeZone(),
             }),
         });
         const json = await res.json();
         if (res.status !== 200) {
-            throw Error(json?.message || `Non-200 status code: ${res.status}`);
+            throw Error(
+                json?.detail ||
+                    json?.message ||
+                    `Non-200 status code: ${res.status}`
+            );
         }
         return json;
     };
@@ -365,22 +455,68 @@ export function useEditEvent() {
     });
 }
 
+async function restoreEvent(uuid: string) {
+    const res = await fetch(api.ROUTES.RESTORE(uuid), {
+        method: "POST",
+    });
+    const json = await res.json();
+    if (res.status !== 200) {
+        throw Error(
+            json?.detail ||
+                json?.message ||
+                `Non-200 status code: ${res.status}`
+        );
+    }
+    return json;
+}
+
 export function useDeleteEvent() {
     const { addToast } = useStorePick("addToast");
 
-    const mutationFn = async ({ uuid }: { uuid: string }) => {
+    const mutationFn = async ({
+        uuid,
+    }: {
+        uuid: string;
+        eventInterval: CalInterval;
+    }) => {
         const res = await fetch(api.ROUTES.DELETE(uuid), {
             method: "DELETE",
         });
         const json = await res.json();
         if (res.status !== 200) {
-            throw Error(json?.message || `Non-200 status code: ${res.status}`);
+            throw Error(
+                json?.detail ||
+                    json?.message ||
+                    `Non-200 status code: ${res.status}`
+            );
         }
         return json;
     };
     return useMutation(mutationFn, {
-        onSuccess: () => {
-            addToast("Success", "Delete Event", "Successfully deleted event");
+        onSuccess: (_, { uuid, eventInterval }) => {
+            // Deleted events stay in the trash until purged, so the delete
+            // can be taken back for a while.
+            addToast(
+                "Success",
+                "Delete Event",
+                "Moved event to the trash",
+                10,
+                {
+                    label: "Undo",
+                    onClick: () => {
+                        restoreEvent(uuid)
+                            .then(() => invalidateOnEventChange(eventInterval))
+                            .catch((err) =>
+                                addToast(
+                                    "Error",
+                                    "Restore Event",
+                                    "Restoring event was unsuccessful. Failure reason: " +
+                                        err.message
+                                )
+                            );
+                    },
+                }
+            );
         },
         onError: (err) => {
             let message = "";
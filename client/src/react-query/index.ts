@@ -9,6 +9,8 @@ export const queryClient = new QueryClient({
 });
 
 export const queryKeys = {
+    calendar: () => ["calendar"],
+    tags: () => ["tags"],
     events: {
         getAll: () => ["events"],
         getByDay: (date: string) => ["events", "day", date],
@@ -4,6 +4,27 @@ import { CalInterval, Moment } from "../types";
 
 export * from "date-fns";
 
+type WeekStart = 0 | 1 | 2 | 3 | 4 | 5 | 6;
+
+/**
+ * Weeks start on weekStartsOn (0 is Sunday) and week 1 of a year is the first
+ * week with at least four days in it, as on the server. The default is the
+ * ISO week until setWeekStart is called with the server's setting.
+ */
+const weekOptions: { weekStartsOn: WeekStart; firstWeekContainsDate: number } =
+    {
+        weekStartsOn: 1,
+        firstWeekContainsDate: 4,
+    };
+
+export function setWeekStart(weekStartsOn: WeekStart) {
+    weekOptions.weekStartsOn = weekStartsOn;
+}
+
+export function getWeekOptions() {
+    return weekOptions;
+}
+
 export function getMonth(date: Date) {
     return df.getMonth(date) + 1;
 }
@@ -65,9 +86,10 @@ export function eachDayInMonth(date: Date) {
 
 export function eachWeekInMonth(date: Date) {
     const monthInterval = getMonthInterval(date, false);
-    const startOfWeekArray = df.eachWeekOfInterval(monthInterval, {
-        weekStartsOn: 1,
-    });
+    const startOfWeekArray = df.eachWeekOfInterval(
+        monthInterval,
+        weekOptions
+    );
     return startOfWeekArray.map((startOfWeek) => eachDayInWeek(startOfWeek));
 }
 
@@ -84,10 +106,10 @@ export function getDayInterval(date: Date, halfOpen = true): CalInterval {
 }
 
 export function getWeekInterval(date: Date, halfOpen = true): CalInterval {
-    const start = df.startOfISOWeek(date);
+    const start = df.startOfWeek(date, weekOptions);
     const end = halfOpen
-        ? df.startOfISOWeek(incWeek(date))
-        : df.endOfISOWeek(date);
+        ? df.startOfWeek(incWeek(date), weekOptions)
+        : df.endOfWeek(date, weekOptions);
     return { start, end };
 }
 
@@ -119,7 +141,10 @@ export function getDaySpec(date: Date): [number, number] {
 }
 
 export function getWeekSpec(date: Date): [number, number] {
-    return [df.getYear(date), df.getISOWeek(date)];
+    return [
+        df.getWeekYear(date, weekOptions),
+        df.getWeek(date, weekOptions),
+    ];
 }
 
 export function getMonthSpec(date: Date): [number, number] {
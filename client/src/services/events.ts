@@ -66,3 +66,9 @@ export function viewDateFilter(viewDate: Date) {
     return (e: CalEvent) =>
         areIntervalsOverlapping(pick(["start", "end"], e), interval);
 }
+
+export function tagFilter(tagNames: string[]) {
+    return (e: CalEvent) =>
+        tagNames.length === 0 ||
+        (e.tags ?? []).some((tag) => tagNames.includes(tag.name));
+}
@@ -1,12 +1,15 @@
 import { StateCreator } from "zustand";
 
-import { modify, mergeLeft, always, __ } from "ramda";
+import { modify, mergeLeft, always, without, __ } from "ramda";
 import { CalEvent } from "../types";
 
 export interface EventFilterSlice {
     eventFilter: { [P in keyof CalEvent]: (arg: CalEvent[P]) => boolean };
     setEventFilter: (filter: Partial<EventFilterSlice["eventFilter"]>) => void;
     resetEventFilter: () => void;
+    // Events are shown if they have any of the tags, or all if none is set.
+    tagNames: string[];
+    toggleTagName: (name: string) => void;
 }
 
 const zeroEventFilter = {
@@ -25,6 +28,14 @@ export const createEventFilterSlice: StateCreator<EventFilterSlice> = (
         set((state) => modify("eventFilter", mergeLeft(eventFilter), state));
     },
     resetEventFilter() {
-        set({ eventFilter: zeroEventFilter });
+        set({ eventFilter: zeroEventFilter, tagNames: [] });
+    },
+    tagNames: [],
+    toggleTagName(name) {
+        set((state) => ({
+            tagNames: state.tagNames.includes(name)
+                ? without([name], state.tagNames)
+                : [...state.tagNames, name],
+        }));
     },
 });
@@ -4,12 +4,18 @@ const TOAST_LIMIT = 5;
 
 type ToastStatus = "Success" | "Error" | "Warning";
 
+interface ToastAction {
+    label: string;
+    onClick: () => void;
+}
+
 interface Toast {
     status: ToastStatus;
     id: string;
     title: string;
     description: string;
     duration: number; // duration in seconds
+    action?: ToastAction;
 }
 
 let genId: () => string;
@@ -25,7 +31,8 @@ function newToast(
     status: ToastStatus,
     title: string,
     description: string,
-    duration: number = 5
+    duration: number = 5,
+    action?: ToastAction
 ) {
     const id = genId();
     return {
@@ -34,6 +41,7 @@ function newToast(
         title,
         description,
         duration,
+        action,
     };
 }
 
@@ -44,15 +52,16 @@ export interface ToastSlice {
         status: ToastStatus,
         title: string,
         description: string,
-        duration?: number
+        duration?: number,
+        action?: ToastAction
     ) => void;
     removeToast: (toasId: string) => void;
 }
 
 export const createToastSlice: StateCreator<ToastSlice> = (set, get) => ({
     toasts: [],
-    addToast(status, title, description, duration) {
-        const toast = newToast(status, title, description, duration);
+    addToast(status, title, description, duration, action) {
+        const toast = newToast(status, title, description, duration, action);
         const timeout = setTimeout(
             () => get().removeToast(toast.id),
             toast.duration * 1000
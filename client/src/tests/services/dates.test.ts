@@ -7,7 +7,8 @@ import {
     decDay,
     decWeek,
     decMonth,
-
+    getWeekSpec,
+    setWeekStart,
 } from "../../services/dates";
 
 describe("increments and decrements", () => {
@@ -53,3 +54,19 @@ describe("increments and decrements", () => {
         expect(dateAfter.toISOString()).toBe("1999-12-01T00:00:00.000Z");
     });
 });
+
+describe("week numbers", () => {
+    const newYear = new Date(2023, 0, 1); // a Sunday
+
+    afterEach(() => setWeekStart(1));
+
+    it("numbers ISO weeks by default", () => {
+        expect(getWeekSpec(newYear)).toEqual([2022, 52]);
+    });
+
+    it("numbers weeks starting on Sunday like the server", () => {
+        setWeekStart(0);
+        expect(getWeekSpec(newYear)).toEqual([2023, 1]);
+        expect(getWeekSpec(new Date(2022, 11, 31))).toEqual([2022, 52]);
+    });
+});
@@ -7,10 +7,17 @@ export function isCalInterval(value: any): value is CalInterval {
     return "start" in value && "end" in value;
 }
 
+export interface CalTag {
+    id: number;
+    name: string;
+    color: string;
+}
+
 export interface CalEvent extends CalInterval {
     title: string;
     description: string;
     uuid: string;
+    tags?: CalTag[];
 }
 
 export function isCalEvent(value: any): value is CalEvent {
@@ -13,8 +13,12 @@ module.exports = {
     },
     entry: path.join(__dirname, "src", "index.tsx"),
     output: {
-        filename: "bundle.js",
+        // Hashed names let the server cache the bundle forever, the absolute
+        // public path keeps it loading on deep links like /week/2024/12.
+        filename: "bundle.[contenthash].js",
         path: path.join(__dirname, "dist"),
+        publicPath: "/",
+        clean: true,
     },
     module: {
         rules: [